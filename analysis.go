@@ -0,0 +1,427 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      geometric statistics, box-counting fractal dimension, self-intersection checks and Horton-Strahler statistics of
+ *      turtle interpretations.
+ *  Types:
+ *      Analysis
+ *          Geometric statistics of a turtle interpretation.
+ *      BoxCount
+ *          Number of grid boxes of a given size met by a drawing.
+ *      HortonOrder
+ *          Horton stream statistics of a Horton-Strahler order.
+ *  Functions:
+ *      Analyze(angle float64) Analysis
+ *          Computes the geometric statistics of the latest generated turtle commands.
+ *  Remarks:
+ *      Lengths are measured in turtle strides. The box-counting dimension is the slope of the least-squares line
+ *      through the points (log 1/size, log boxes), over grid sizes halving from half the larger side of the bounding
+ *      box down to the mean segment length, below which any drawing looks one-dimensional.
+ *      A drawing is self-avoiding when no line segment retraces another, no two segments meet elsewhere than at a shared
+ *      endpoint, and no vertex joins more than two segments. A self-avoiding drawing is space-filling when its vertices
+ *      lie on a square or triangular lattice whose spacing is the segment length and it visits every lattice point of
+ *      its bounding box, as the Hilbert, Moore and Peano curves do. The Gosper curve lies on a triangular lattice but
+ *      fills a hexagonal island, so that only its lattice coverage is reported.
+ *      The Horton-Strahler orders are those of the nodes of BranchStructure. Horton's bifurcation and length ratios are
+ *      the geometric means of the ratios between successive orders, i.e., (N1/Nk)^(1/(k-1)) and (Lk/L1)^(1/(k-1)) for
+ *      the stream counts N and mean stream lengths L of the orders 1 to k.
+ *  History: v1.16.0 - October 18, 2026 - Original release.
+ *           v1.18.0 - October 18, 2026 - Added the self-intersection and space-filling checks.
+ *           v1.20.0 - October 18, 2026 - Added the Horton-Strahler statistics.
+ *           v1.24.0 - October 18, 2026 - Added the triangular lattice.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "fmt"
+    "math"
+    "strings"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type Analysis struct {
+    LENGTH        float64       //total length of the drawn line segments
+    SEGMENTS      int           //number of drawn line segments
+    POLYGONS      int           //number of filled polygons
+    XMIN          float64       //bounding box of all turtle positions
+    XMAX          float64
+    YMIN          float64
+    YMAX          float64
+    ASPECT        float64       //width over height of the bounding box; +Inf for a horizontal drawing, NaN for a point
+    BRANCHES      int           //number of branches, i.e., of "[" symbols
+    TIPS          int           //number of drawn vertices met by a single line segment, i.e., of free ends
+    MAX_DEPTH     int           //deepest branch nesting
+    DIMENSION     float64       //box-counting fractal dimension estimate; NaN if fewer than two grid sizes apply
+    BOX_COUNTS    []BoxCount    //box counts from the largest grid size to the smallest
+    REPEATS       int           //number of line segments retracing an earlier one
+    CROSSINGS     int           //number of segment pairs meeting elsewhere than at a shared endpoint, overlaps included
+    OVERLAPS      int           //number of drawn vertices joining more than two distinct line segments
+    GRID_POINTS   int           //points of the square or triangular lattice in the bounding box; 0 if off both lattices
+    GRID_VISITED  int           //number of lattice points visited by the drawn vertices
+    SELF_AVOIDING bool          //true if there are no repeats, crossings or overlapping vertices
+    SPACE_FILLING bool          //true if the drawing is self-avoiding and visits every lattice point
+    STRAHLER      int           //Horton-Strahler order of the drawing, i.e., the highest order of its branches
+    HORTON        []HortonOrder //stream statistics from order 1 to STRAHLER
+    BIFURCATION   float64       //Horton's bifurcation ratio; NaN below order 2
+    LENGTH_RATIO  float64       //Horton's stream-length ratio; NaN below order 2
+}
+type BoxCount struct {
+    SIZE  float64 //side of the grid boxes in turtle strides
+    BOXES int     //number of boxes met by the line segments and polygon outlines
+}
+type HortonOrder struct {
+    ORDER       int     //Horton-Strahler order
+    STREAMS     int     //number of streams, i.e., of maximal chains of branch nodes of this order
+    SEGMENTS    int     //number of line segments
+    LENGTH      float64 //total length of the line segments
+    MEAN_LENGTH float64 //mean length of the streams
+}
+func Analyze(angle float64) Analysis {
+/*         Purpose : Computes the geometric statistics of the latest generated turtle commands.
+ *       Arguments : angle = production angle in degrees.
+ *         Returns : the statistics.
+ * Externals -  In : TurtleCmds, _degs2rads, _turtleHistory, _turtleStatus, _vertexTolerance
+ * Externals - Out : None.
+ *       Functions : boxCounts, branchNodes, crossings, gridCoverage, halt, hortonStatistics, logo2Geometry,
+ *                   strahlerOrders, weldPoints
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { } ; ,
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - Duplicate line segments count once towards the tips, the crossings and the overlapping vertices.
+ *                   - The self-intersection checks apply to the line segments only, not to the polygons.
+ *                   - A vertex lying within 1e-6 strides of an earlier one is merged with it.
+ *         History : v1.16.0 - October 18, 2026 - Original release.
+ *                   v1.18.0 - October 18, 2026 - Added the self-intersection and space-filling checks.
+ *                   v1.20.0 - October 18, 2026 - Added the Horton-Strahler statistics.
+ *                   v1.24.0 - October 18, 2026 - Merged the vertices by searching the neighbouring cells.
+ */
+    if TurtleCmds == "" { halt("the turtle commands were not generated") }
+    if angle      == 0. { halt("the production angle is zero") }
+
+    var( analysis Analysis
+         degrees  = make(map[int]int)
+         seen     = make(map[_edge]bool)
+         unique   []_segment
+    )
+    //Interpret the turtle commands
+    geometry := logo2Geometry(TurtleCmds, angle, nil)
+    weld     := weldPoints(_vertexTolerance, len(geometry.SEGMENTS))
+    analysis.SEGMENTS, analysis.POLYGONS = len(geometry.SEGMENTS), len(geometry.POLYGONS)
+    analysis.XMIN, analysis.XMAX, analysis.YMIN, analysis.YMAX = geometry.XMIN, geometry.XMAX, geometry.YMIN, geometry.YMAX
+    analysis.ASPECT = (geometry.XMAX - geometry.XMIN) / (geometry.YMAX - geometry.YMIN)
+    //Measure the segments and count the degree of their vertices
+    for _, segment := range geometry.SEGMENTS {
+        analysis.LENGTH += math.Hypot(segment.TO.X - segment.FROM.X, segment.TO.Y - segment.FROM.Y)
+        _, from := weld(segment.FROM)
+        _, to   := weld(segment.TO)
+        if from == to { continue }
+        if seen[_edge{from, to}] || seen[_edge{to, from}] {
+            analysis.REPEATS++
+            continue
+        }
+        seen[_edge{from, to}] = true
+        unique = append(unique, segment)
+        degrees[from]++
+        degrees[to]++
+    }
+    for _, degree := range degrees {
+        switch {
+            case degree == 1: analysis.TIPS++
+            case degree > 2 : analysis.OVERLAPS++
+        }
+    }
+    //Count the branches and their nesting
+    depth := 0
+    for _, symbol := range TurtleCmds {
+        switch symbol {
+            case '[':
+                analysis.BRANCHES++
+                depth++
+                if depth > analysis.MAX_DEPTH { analysis.MAX_DEPTH = depth }
+            case ']':
+                depth--
+        }
+    }
+    //Estimate the box-counting dimension
+    analysis.BOX_COUNTS = boxCounts(&geometry, analysis.LENGTH)
+    analysis.DIMENSION  = math.NaN()
+    if len(analysis.BOX_COUNTS) > 1 {
+        //least-squares slope of log(boxes) against log(1/size)
+        var sumX, sumY, sumXX, sumXY float64
+        n := float64(len(analysis.BOX_COUNTS))
+        for _, count := range analysis.BOX_COUNTS {
+            x, y  := -math.Log(count.SIZE), math.Log(float64(count.BOXES))
+            sumX  += x
+            sumY  += y
+            sumXX += x * x
+            sumXY += x * y
+        }
+        analysis.DIMENSION = (n * sumXY - sumX * sumY) / (n * sumXX - sumX * sumX)
+    }
+    //Check for self-intersections and lattice coverage
+    analysis.CROSSINGS     = crossings(unique)
+    analysis.SELF_AVOIDING = analysis.REPEATS == 0 && analysis.CROSSINGS == 0 && analysis.OVERLAPS == 0
+    analysis.GRID_POINTS, analysis.GRID_VISITED = gridCoverage(unique)
+    analysis.SPACE_FILLING = analysis.SELF_AVOIDING && analysis.GRID_POINTS > 0 &&
+                             analysis.GRID_VISITED == analysis.GRID_POINTS
+    //Order the branches
+    tree, _ := branchNodes(&geometry)
+    strahlerOrders(&tree)
+    analysis.STRAHLER, analysis.HORTON, analysis.BIFURCATION, analysis.LENGTH_RATIO = hortonStatistics(&tree)
+    return analysis
+} //end func Analyze
+func(analysis Analysis) String() string {
+/*         Purpose : Formats the statistics as a multi-line report.
+ *       Arguments : None.
+ *         Returns : the report.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : None.
+ *         History : v1.16.0 - October 18, 2026 - Original release.
+ *                   v1.18.0 - October 18, 2026 - Added the self-intersection and space-filling checks.
+ *                   v1.20.0 - October 18, 2026 - Added the Horton-Strahler statistics.
+ */
+    var report strings.Builder
+    fmt.Fprintf(&report, "length     %.4f strides in %d segments, %d polygons\n", analysis.LENGTH, analysis.SEGMENTS,
+                analysis.POLYGONS)
+    fmt.Fprintf(&report, "bounds     [%.4f, %.4f] x [%.4f, %.4f], aspect %.4f\n", analysis.XMIN, analysis.XMAX,
+                analysis.YMIN, analysis.YMAX, analysis.ASPECT)
+    fmt.Fprintf(&report, "branches   %d, tips %d, max depth %d\n", analysis.BRANCHES, analysis.TIPS, analysis.MAX_DEPTH)
+    fmt.Fprintf(&report, "dimension  %.4f\n", analysis.DIMENSION)
+    for _, count := range analysis.BOX_COUNTS {
+        fmt.Fprintf(&report, "  box %12.6f: %d\n", count.SIZE, count.BOXES)
+    }
+    fmt.Fprintf(&report, "self-check %d repeats, %d crossings, %d overlapping vertices, self-avoiding %t\n",
+                analysis.REPEATS, analysis.CROSSINGS, analysis.OVERLAPS, analysis.SELF_AVOIDING)
+    if analysis.GRID_POINTS > 0 {
+        fmt.Fprintf(&report, "grid       %d of %d lattice points visited, space-filling %t\n", analysis.GRID_VISITED,
+                    analysis.GRID_POINTS, analysis.SPACE_FILLING)
+    }
+    fmt.Fprintf(&report, "strahler   order %d, bifurcation ratio %.4f, length ratio %.4f\n", analysis.STRAHLER,
+                analysis.BIFURCATION, analysis.LENGTH_RATIO)
+    for _, horton := range analysis.HORTON {
+        fmt.Fprintf(&report, "  order %2d: %d streams, %d segments, length %.4f, mean stream length %.4f\n", horton.ORDER,
+                    horton.STREAMS, horton.SEGMENTS, horton.LENGTH, horton.MEAN_LENGTH)
+    }
+    return report.String()
+} //end func String
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+const _maxBoxScales = 12 //most grid sizes used for the box-counting dimension
+func boxCounts(geometry *_geometry, length float64) (counts []BoxCount) {
+/*         Purpose : Counts the grid boxes met by the line segments and polygon outlines for halving grid sizes.
+ *       Arguments : geometry = turtle geometry.
+ *                   length   = total length of the line segments.
+ *         Returns : slice of box counts, from the largest grid size to the smallest.
+ * Externals -  In : _maxBoxScales
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : - The grid sizes run from half the larger side of the bounding box down to the mean segment length,
+ *                     or the mean polygon edge for drawings made only of polygons.
+ *                   - Each edge is sampled every quarter box, so that a box merely clipped at a corner may be missed.
+ *         History : v1.16.0 - October 18, 2026 - Original release.
+ */
+    var edges [][2]_point
+    for _, segment := range geometry.SEGMENTS {
+        edges = append(edges, [2]_point{segment.FROM, segment.TO})
+    }
+    outline := 0.
+    for _, polygon := range geometry.POLYGONS {
+        for k, vertex := range polygon.VERTICES {
+            next   := polygon.VERTICES[(k+1) % len(polygon.VERTICES)]
+            edges   = append(edges, [2]_point{vertex, next})
+            outline += math.Hypot(next.X - vertex.X, next.Y - vertex.Y)
+        }
+    }
+    if len(edges) == 0 { return }
+    span    := math.Max(geometry.XMAX - geometry.XMIN, geometry.YMAX - geometry.YMIN)
+    meanLen := (length + outline) / float64(len(edges))
+    if !(span > 0.) || !(meanLen > 0.) { return }
+    //the sizes are enlarged by a hair so that the far sides of the bounding box fall in the last row and column
+    for size := 0.5 * span * (1. + 1e-9); size >= meanLen && len(counts) < _maxBoxScales; size *= 0.5 {
+        boxes := make(map[[2]int64]bool)
+        for _, edge := range edges {
+            dx, dy := edge[1].X - edge[0].X, edge[1].Y - edge[0].Y
+            steps  := int(math.Ceil(4. * math.Hypot(dx, dy) / size))
+            for k := 0; k <= steps; k++ {
+                t := 0.
+                if steps > 0 { t = float64(k) / float64(steps) }
+                boxes[[2]int64{int64(math.Floor((edge[0].X + t * dx - geometry.XMIN) / size)),
+                                int64(math.Floor((edge[0].Y + t * dy - geometry.YMIN) / size))}] = true
+            }
+        }
+        counts = append(counts, BoxCount{size, len(boxes)})
+    }
+    return
+} //end func boxCounts
+func crossings(segments []_segment) (count int) {
+/*         Purpose : Counts the pairs of line segments meeting elsewhere than at a shared endpoint.
+ *       Arguments : segments = distinct line segments of non-zero length.
+ *         Returns : the number of such pairs.
+ * Externals -  In : _vertexTolerance
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : - Proper crossings, T-junctions and collinear overlaps all count.
+ *                   - Candidate pairs are found by hashing the segments on a grid of cells the size of the mean segment
+ *                     length, so that the check runs in about linear time on drawings of even strides.
+ *         History : v1.18.0 - October 18, 2026 - Original release.
+ */
+    if len(segments) < 2 { return }
+    type cellKey struct {
+        X, Y int64
+    }
+    var( cells   = make(map[cellKey][]int)
+         checked = make([]int, len(segments)) //index+1 of the segment last checked against each segment
+         meanLen float64
+    )
+    for _, segment := range segments {
+        meanLen += math.Hypot(segment.TO.X - segment.FROM.X, segment.TO.Y - segment.FROM.Y)
+    }
+    meanLen /= float64(len(segments))
+    cellRange := func(a, b float64) (int64, int64) {
+        return int64(math.Floor((math.Min(a, b) - _vertexTolerance) / meanLen)),
+               int64(math.Floor((math.Max(a, b) + _vertexTolerance) / meanLen))
+    }
+    cross := func(ux, uy, vx, vy float64) float64 { return ux * vy - uy * vx }
+    for i, a := range segments {
+        xLow, xHigh := cellRange(a.FROM.X, a.TO.X)
+        yLow, yHigh := cellRange(a.FROM.Y, a.TO.Y)
+        for x := xLow; x <= xHigh; x++ {
+            for y := yLow; y <= yHigh; y++ {
+                key := cellKey{x, y}
+                for _, j := range cells[key] {
+                    if checked[j] == i + 1 { continue }
+                    checked[j] = i + 1
+                    b := segments[j]
+                    //parametrize a as FROM + t*d1 and b as FROM + s*d2
+                    d1x, d1y := a.TO.X - a.FROM.X, a.TO.Y - a.FROM.Y
+                    d2x, d2y := b.TO.X - b.FROM.X, b.TO.Y - b.FROM.Y
+                    len1, len2 := math.Hypot(d1x, d1y), math.Hypot(d2x, d2y)
+                    ex, ey := b.FROM.X - a.FROM.X, b.FROM.Y - a.FROM.Y
+                    denom  := cross(d1x, d1y, d2x, d2y)
+                    if math.Abs(denom) > _vertexTolerance * len1 * len2 { //not parallel
+                        t, s := cross(ex, ey, d2x, d2y) / denom, cross(ex, ey, d1x, d1y) / denom
+                        e1, e2 := _vertexTolerance / len1, _vertexTolerance / len2
+                        if t < -e1 || t > 1. + e1 || s < -e2 || s > 1. + e2 { continue }
+                        atEnd1 := t <= e1 || t >= 1. - e1
+                        atEnd2 := s <= e2 || s >= 1. - e2
+                        if !(atEnd1 && atEnd2) { count++ }
+                        continue
+                    }
+                    if math.Abs(cross(d1x, d1y, ex, ey)) > _vertexTolerance * len1 { continue } //parallel lines
+                    //collinear segments overlap if their projections on a share more than a point
+                    t0 := (ex * d1x + ey * d1y) / (len1 * len1)
+                    t1 := ((b.TO.X - a.FROM.X) * d1x + (b.TO.Y - a.FROM.Y) * d1y) / (len1 * len1)
+                    if (math.Min(1., math.Max(t0, t1)) - math.Max(0., math.Min(t0, t1))) * len1 > _vertexTolerance {
+                        count++
+                    }
+                }
+                cells[key] = append(cells[key], i)
+            }
+        }
+    }
+    return
+} //end func crossings
+func gridCoverage(segments []_segment) (points, visited int) {
+/*         Purpose : Counts the lattice points visited by a drawing lying on a square or triangular lattice.
+ *       Arguments : segments = distinct line segments of non-zero length.
+ *         Returns : points  = number of lattice points within the bounding box of the vertices, or 0 if the segments
+ *                             are not all of one length or their vertices are off both lattices.
+ *                   visited = number of distinct lattice points met by the vertices.
+ * Externals -  In : _degs2rads, _vertexTolerance
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : - The lattice is aligned on the first segment, whose length sets its spacing, and so is the
+ *                     bounding box.
+ *                   - The square lattice is tried first, then the triangular one, whose second basis vector lies at
+ *                     60 degrees to the first, as for the Gosper curve.
+ *         History : v1.18.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Added the triangular lattice.
+ */
+    if len(segments) == 0 { return }
+    const slack = 1e-9 //lattice spacings
+    var( origin  = segments[0].FROM
+         ux, uy  = segments[0].TO.X - origin.X, segments[0].TO.Y - origin.Y
+         spacing = math.Hypot(ux, uy)
+    )
+    for _, segment := range segments {
+        if math.Abs(math.Hypot(segment.TO.X - segment.FROM.X, segment.TO.Y - segment.FROM.Y) - spacing) > _vertexTolerance {
+            return 0, 0
+        }
+    }
+    //Locate the vertices on the square lattice, then on the triangular one
+lattices:
+    for _, angle := range []float64{90., 60.} {
+        var( cos, sin   = map[bool]float64{true: 0., false: math.Cos(angle * _degs2rads)} [angle == 90.],
+                          math.Sin(angle * _degs2rads)
+             nodes      = make(map[[2]int64]bool)
+             xMin, xMax = math.Inf(1), math.Inf(-1)
+             bMin, bMax int64 = math.MaxInt64, math.MinInt64
+        )
+        for _, segment := range segments {
+            for _, p := range []_point{segment.FROM, segment.TO} {
+                //coordinates along the first segment and its perpendicular, then over the lattice basis
+                x := ((p.X - origin.X) * ux + (p.Y - origin.Y) * uy) / (spacing * spacing)
+                y := ((p.Y - origin.Y) * ux - (p.X - origin.X) * uy) / (spacing * spacing)
+                b := y / sin
+                a := x - b * cos
+                ia, ib := math.Floor(a + 0.5), math.Floor(b + 0.5)
+                if math.Abs(a - ia) * spacing > _vertexTolerance || math.Abs(b - ib) * spacing > _vertexTolerance {
+                    continue lattices
+                }
+                node := [2]int64{int64(ia), int64(ib)}
+                nodes[node] = true
+                xMin, xMax  = math.Min(xMin, x), math.Max(xMax, x)
+                if node[1] < bMin { bMin = node[1] }
+                if node[1] > bMax { bMax = node[1] }
+            }
+        }
+        //count the lattice points of each row, parallel to the first segment, within the bounding box
+        for row := bMin; row <= bMax; row++ {
+            shift  := float64(row) * cos
+            points += int(math.Floor(xMax - shift + slack) - math.Ceil(xMin - shift - slack)) + 1
+        }
+        return points, len(nodes)
+    }
+    return 0, 0
+} //end func gridCoverage
+func hortonStatistics(tree *BranchTree) (order int, hortons []HortonOrder, bifurcation, lengthRatio float64) {
+/*         Purpose : Computes the Horton stream statistics of an ordered branch tree.
+ *       Arguments : tree = branch tree with Horton-Strahler orders.
+ *         Returns : order       = highest Horton-Strahler order, or 0 for an empty tree.
+ *                   hortons     = stream statistics from order 1 to the highest order.
+ *                   bifurcation = Horton's bifurcation ratio, or NaN below order 2.
+ *                   lengthRatio = Horton's stream-length ratio, or NaN below order 2.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : A stream starts at each node whose parent, if any, has a different order.
+ *         History : v1.20.0 - October 18, 2026 - Original release.
+ */
+    bifurcation, lengthRatio = math.NaN(), math.NaN()
+    for _, node := range tree.NODES {
+        if node.STRAHLER > order { order = node.STRAHLER }
+    }
+    if order == 0 { return }
+    hortons = make([]HortonOrder, order)
+    for k := range hortons {
+        hortons[k].ORDER = k + 1
+    }
+    for _, node := range tree.NODES {
+        horton := &hortons[node.STRAHLER - 1]
+        if node.PARENT < 0 || tree.NODES[node.PARENT].STRAHLER != node.STRAHLER { horton.STREAMS++ }
+        horton.SEGMENTS += node.SEGMENTS
+        horton.LENGTH   += node.LENGTH
+    }
+    for k := range hortons {
+        hortons[k].MEAN_LENGTH = hortons[k].LENGTH / float64(hortons[k].STREAMS)
+    }
+    if order > 1 {
+        exponent   := 1. / float64(order - 1)
+        bifurcation = math.Pow(float64(hortons[0].STREAMS) / float64(hortons[order-1].STREAMS), exponent)
+        lengthRatio = math.Pow(hortons[order-1].MEAN_LENGTH / hortons[0].MEAN_LENGTH, exponent)
+    }
+    return
+} //end func hortonStatistics
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of analysis.go
@@ -0,0 +1,41 @@
+/*==============================================================================================================================
+ * Purpose: Command-line tool for authoring L-system grammars with the "lsystems" package.
+ *   Usage: lsys repl
+ *              Starts an interactive session for setting a grammar, stepping its derivation one order at a time and
+ *              previewing the result.
+ *          lsys watch <grammar file> -o <output file> [flags]
+ *              Re-renders a grammar file each time it is saved. See lsystems.ParseGrammar for the file format.
+ *============================================================================================================================*/
+package main
+
+import (
+    "fmt"
+    "github.com/ybeaudoin/go-lsystems"
+    "os"
+)
+func main() {
+    if len(os.Args) < 2 {
+        usage()
+        os.Exit(2)
+    }
+    lsystems.ProgressBars = false //the standard output belongs to the session
+    switch os.Args[1] {
+        case "repl":
+            repl(os.Stdin, os.Stdout)
+        case "watch":
+            if err := watch(os.Args[2:], os.Stdout); err != nil {
+                fmt.Fprintln(os.Stderr, "lsys watch: " + err.Error())
+                os.Exit(2)
+            }
+        case "help", "-h", "-help", "--help":
+            usage()
+        default:
+            fmt.Fprintf(os.Stderr, "lsys: unknown command %q\n", os.Args[1])
+            usage()
+            os.Exit(2)
+    }
+}
+func usage() {
+    fmt.Fprintln(os.Stderr, "usage: lsys repl                                 start an interactive grammar session")
+    fmt.Fprintln(os.Stderr, "       lsys watch <grammar> -o <output> [flags]  re-render a grammar file on each save")
+}
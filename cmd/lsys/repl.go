@@ -0,0 +1,276 @@
+/*==============================================================================================================================
+ * Purpose: Interactive session stepping the derivation of a grammar with the Deterministic, Stochastic and HogewegHesper
+ *          engines of the "lsystems" package. Type "help" at the prompt for the commands.
+ * Remarks: The engines halt the program on invalid input, so each step is validated beforehand by lsystems.Grammar.
+ *============================================================================================================================*/
+package main
+
+import (
+    "bufio"
+    "fmt"
+    "github.com/ybeaudoin/go-lsystems"
+    "io"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+)
+type _session struct {
+    GRAMMAR lsystems.Grammar  //grammar being edited; its order is ignored
+    ORDER   int               //order of CMDS
+    CMDS    string            //turtle commands of the current order
+    PREVIEW string            //SVG or PNG file rewritten after each step, or ""
+    WIDTH   int               //preview size in pixels
+    HEIGHT  int
+    LIMIT   int               //most symbols allowed in a derivation
+}
+var( _replHelp = `commands:
+  mode deterministic|stochastic|hogeweg   select the engine (clears the rules)
+  axiom <symbols>                         set the axiom
+  angle <degrees>                         set the production angle
+  rule <P>=<successor> [weight]           add or replace a rule; P is a single symbol (deterministic),
+                                          F with an optional weight (stochastic) or L<a>R (hogeweg)
+  rules                                   list the grammar, the deterministic rules in the order added,
+                                          which is their precedence as in grammar files
+  clear                                   remove all the rules
+  step [n]                                derive the next n orders (default 1)
+  reset                                   return to order 0
+  stats                                   show the length and symbol counts of the current order
+  show [n]                                print the first n symbols of the current order (default 200)
+  preview <file.svg|file.png>|off         write a preview after each step
+  size <width> <height>                   set the preview size in pixels
+  limit <n>                               refuse derivations longer than n symbols
+  quit                                    end the session
+Changing the mode, axiom or rules returns to order 0.`
+     _reContext = regexp.MustCompile(`^(0|1)<(0|1)>(0|1)$`)
+)
+func repl(in io.Reader, out io.Writer) {
+    session := _session{GRAMMAR: lsystems.Grammar{ENGINE: "deterministic", AXIOM: "F", ANGLE: 90.,
+                                                  RULES: map[string]string{}},
+                        WIDTH: 512, HEIGHT: 512, LIMIT: 1000000}
+    session.reset()
+    scanner := bufio.NewScanner(in)
+    scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+    fmt.Fprintln(out, `lsys: type "help" for the commands`)
+    for {
+        fmt.Fprintf(out, "lsys[%d]> ", session.ORDER)
+        if !scanner.Scan() {
+            fmt.Fprintln(out)
+            return
+        }
+        fields := strings.Fields(scanner.Text())
+        if len(fields) == 0 { continue }
+        if fields[0] == "quit" || fields[0] == "exit" { return }
+        msg, err := session.execute(fields[0], fields[1:])
+        switch {
+            case err != nil: fmt.Fprintln(out, "error: " + err.Error())
+            case msg != "":  fmt.Fprintln(out, msg)
+        }
+    }
+}
+func (session *_session) execute(command string, args []string) (msg string, err error) {
+    //Runs a session command, returning its report or an error.
+    argCount := func(min, max int) error {
+        if len(args) < min || len(args) > max { return fmt.Errorf("%s: wrong number of arguments", command) }
+        return nil
+    }
+    switch command {
+        case "help":
+            return _replHelp, nil
+        case "mode":
+            if err = argCount(1, 1); err != nil { return }
+            switch args[0] {
+                case "deterministic", "stochastic", "hogeweg":
+                default: return "", fmt.Errorf("mode: %q is not one of deterministic, stochastic or hogeweg", args[0])
+            }
+            session.GRAMMAR.ENGINE = args[0]
+            session.clearRules()
+            session.reset()
+            return session.describe(), nil
+        case "axiom":
+            if err = argCount(1, 1); err != nil { return }
+            session.GRAMMAR.AXIOM = args[0]
+            session.reset()
+        case "angle":
+            if err = argCount(1, 1); err != nil { return }
+            angle, err := strconv.ParseFloat(args[0], 64)
+            if err != nil || angle == 0. { return "", fmt.Errorf("angle: %q is not a non-zero number", args[0]) }
+            session.GRAMMAR.ANGLE = angle
+            return session.writePreview()
+        case "rule":
+            if err = argCount(1, 2); err != nil { return }
+            if err = session.addRule(args); err != nil { return }
+            session.reset()
+        case "rules":
+            return session.describe(), nil
+        case "clear":
+            session.clearRules()
+            session.reset()
+        case "step":
+            if err = argCount(0, 1); err != nil { return }
+            steps := 1
+            if len(args) == 1 {
+                if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+                    return "", fmt.Errorf("step: %q is not a positive integer", args[0])
+                }
+            }
+            for n := 1; n <= steps; n++ {
+                next, err := session.GRAMMAR.Step(session.CMDS, session.LIMIT)
+                if err != nil && n == 1 { return "", fmt.Errorf("step: %v", err) }
+                if err != nil {
+                    msg = fmt.Sprintf("stopped: %v\n", err)
+                    break
+                }
+                session.ORDER, session.CMDS = session.ORDER + 1, next
+            }
+            msg += fmt.Sprintf("order %d: %d symbols", session.ORDER, len(session.CMDS))
+            if preview, err := session.writePreview(); err != nil {
+                msg += "\nerror: " + err.Error()
+            } else if preview != "" {
+                msg += "\n" + preview
+            }
+            return msg, nil
+        case "reset":
+            session.reset()
+        case "stats":
+            return session.stats(), nil
+        case "show":
+            if err = argCount(0, 1); err != nil { return }
+            count := 200
+            if len(args) == 1 {
+                if count, err = strconv.Atoi(args[0]); err != nil || count < 1 {
+                    return "", fmt.Errorf("show: %q is not a positive integer", args[0])
+                }
+            }
+            if count >= len(session.CMDS) { return session.CMDS, nil }
+            return fmt.Sprintf("%s... (%d more)", session.CMDS[:count], len(session.CMDS) - count), nil
+        case "preview":
+            if err = argCount(1, 1); err != nil { return }
+            if args[0] == "off" {
+                session.PREVIEW = ""
+                return
+            }
+            switch strings.ToLower(filepath.Ext(args[0])) {
+                case ".svg", ".png":
+                default: return "", fmt.Errorf("preview: the file must end in .svg or .png")
+            }
+            session.PREVIEW = args[0]
+            return session.writePreview()
+        case "size":
+            if err = argCount(2, 2); err != nil { return }
+            width, errW  := strconv.Atoi(args[0])
+            height, errH := strconv.Atoi(args[1])
+            if errW != nil || errH != nil || width < 1 || height < 1 || width > 8192 || height > 8192 {
+                return "", fmt.Errorf("size: the width and height must be integers from 1 to 8192")
+            }
+            session.WIDTH, session.HEIGHT = width, height
+            return session.writePreview()
+        case "limit":
+            if err = argCount(1, 1); err != nil { return }
+            limit, err := strconv.Atoi(args[0])
+            if err != nil || limit < 1 { return "", fmt.Errorf("limit: %q is not a positive integer", args[0]) }
+            session.LIMIT = limit
+        default:
+            return "", fmt.Errorf("%q is not a command; type \"help\" for the list", command)
+    }
+    return
+}
+func (session *_session) addRule(args []string) error {
+    //Validates a rule for the current engine and adds it to the grammar.
+    predecessor, successor, found := strings.Cut(args[0], "=")
+    if !found { return fmt.Errorf("rule: %q is not of the form predecessor=successor", args[0]) }
+    weight := 1
+    if len(args) == 2 {
+        if session.GRAMMAR.ENGINE != "stochastic" { return fmt.Errorf("rule: only stochastic rules have weights") }
+        var err error
+        if weight, err = strconv.Atoi(args[1]); err != nil || weight < 1 {
+            return fmt.Errorf("rule: the weight %q is not a positive integer", args[1])
+        }
+    }
+    grammar := &session.GRAMMAR
+    switch grammar.ENGINE {
+        case "deterministic":
+            if len(predecessor) != 1 { return fmt.Errorf("rule: the predecessor must be a single symbol") }
+            if _, ok := grammar.RULES[predecessor]; !ok {
+                grammar.PREDECESSORS = append(grammar.PREDECESSORS, predecessor)
+            }
+            grammar.RULES[predecessor] = successor
+        case "stochastic":
+            if predecessor != "F" { return fmt.Errorf("rule: the stochastic engine only rewrites F") }
+            grammar.CHOICES = append(grammar.CHOICES, successor)
+            grammar.WEIGHTS = append(grammar.WEIGHTS, weight)
+        case "hogeweg":
+            if !_reContext.MatchString(predecessor) {
+                return fmt.Errorf("rule: the predecessor must be of the form L<a>R with 0 or 1 for L, a and R")
+            }
+            grammar.RULES[strings.Join(strings.Split(predecessor[:3], "<"), " < ") + " > " + predecessor[4:]] = successor
+    }
+    return nil
+}
+func (session *_session) clearRules() {
+    //Removes all the rules of the grammar.
+    grammar := &session.GRAMMAR
+    grammar.RULES, grammar.PREDECESSORS, grammar.CHOICES, grammar.WEIGHTS = map[string]string{}, nil, nil, nil
+}
+func (session *_session) reset() {
+    //Returns to order 0.
+    session.ORDER, session.CMDS = 0, session.GRAMMAR.AXIOM
+}
+func (session *_session) describe() string {
+    //Lists the grammar.
+    grammar := session.GRAMMAR
+    lines   := []string{fmt.Sprintf("mode %s\naxiom %s\nangle %g", grammar.ENGINE, grammar.AXIOM, grammar.ANGLE)}
+    if grammar.ENGINE == "stochastic" {
+        for k, successor := range grammar.CHOICES {
+            lines = append(lines, fmt.Sprintf("rule F=%s %d", successor, grammar.WEIGHTS[k]))
+        }
+    } else {
+        predecessors := grammar.PREDECESSORS //deterministic rules in order of precedence
+        if grammar.ENGINE == "hogeweg" {
+            predecessors = nil
+            for predecessor := range grammar.RULES {
+                predecessors = append(predecessors, predecessor)
+            }
+            sort.Strings(predecessors)
+        }
+        for _, predecessor := range predecessors {
+            lines = append(lines, fmt.Sprintf("rule %s=%s", strings.Replace(predecessor, " ", "", -1),
+                                              grammar.RULES[predecessor]))
+        }
+    }
+    return strings.Join(lines, "\n")
+}
+func (session *_session) stats() string {
+    //Reports the length and symbol counts of the current order.
+    counts := map[rune]int{}
+    for _, symbol := range session.CMDS {
+        counts[symbol]++
+    }
+    var symbols []rune
+    for symbol := range counts {
+        symbols = append(symbols, symbol)
+    }
+    sort.Slice(symbols, func(i, j int) bool { return symbols[i] < symbols[j] })
+    report := fmt.Sprintf("order %d: %d symbols", session.ORDER, len(session.CMDS))
+    for _, symbol := range symbols {
+        report += fmt.Sprintf("\n  %c %10d", symbol, counts[symbol])
+    }
+    return report
+}
+func (session *_session) writePreview() (msg string, err error) {
+    //Renders the current order to the preview file, if any.
+    if session.PREVIEW == "" { return "", nil }
+    var image []byte
+    if strings.ToLower(filepath.Ext(session.PREVIEW)) == ".png" {
+        image, err = lsystems.RenderPng(session.CMDS, session.GRAMMAR.ANGLE, session.WIDTH, session.HEIGHT, 1, nil,
+                                        lsystems.RenderOptions{})
+    } else {
+        image, err = lsystems.RenderSvg(session.CMDS, session.GRAMMAR.ANGLE, session.WIDTH, session.HEIGHT, 1., "black", "white",
+                                        lsystems.RenderOptions{})
+    }
+    if err != nil { return "", fmt.Errorf("preview: %v", err) }
+    if err = os.WriteFile(session.PREVIEW, image, 0644); err != nil { return "", fmt.Errorf("preview: %v", err) }
+    return "preview written to " + session.PREVIEW, nil
+}
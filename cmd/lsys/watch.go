@@ -0,0 +1,96 @@
+/*==============================================================================================================================
+ * Purpose: Watch mode polling a grammar file and re-rendering it on each save. Errors in the grammar are printed and the
+ *          previous rendering is kept until they are fixed.
+ *   Usage: lsys watch <grammar file> -o <output file> [-interval 500ms] [-width 800] [-height 800] [-limit 2000000]
+ *          The output format follows the file extension: .svg, .png, or .hpgl/.plt for HP-GL/2 on A4 media.
+ *============================================================================================================================*/
+package main
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "github.com/ybeaudoin/go-lsystems"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+)
+func watch(args []string, out io.Writer) error {
+    //Parses the watch arguments and polls the grammar file until the program is interrupted.
+    var grammarPath string
+    flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+    output   := flags.String("o", "", "output file (.svg, .png, .hpgl or .plt)")
+    interval := flags.Duration("interval", 500 * time.Millisecond, "polling interval")
+    width    := flags.Int("width", 800, "image width in pixels")
+    height   := flags.Int("height", 800, "image height in pixels")
+    limit    := flags.Int("limit", 2000000, "most symbols allowed in a derivation")
+    if len(args) > 0 && !strings.HasPrefix(args[0], "-") { //the grammar file may precede the flags
+        grammarPath, args = args[0], args[1:]
+    }
+    if err := flags.Parse(args); err != nil { return err }
+    if grammarPath == "" && flags.NArg() > 0 { grammarPath = flags.Arg(0) }
+    switch {
+        case grammarPath == "" || *output == "":
+            return errors.New("usage: lsys watch <grammar file> -o <output file> [flags]")
+        case *interval <= 0:
+            return errors.New("the polling interval must be positive")
+        case *width < 1 || *height < 1:
+            return errors.New("the image size must be positive")
+        case *limit < 1:
+            return errors.New("the symbol limit must be positive")
+    }
+    switch strings.ToLower(filepath.Ext(*output)) {
+        case ".svg", ".png", ".hpgl", ".plt":
+        default: return fmt.Errorf("the output file %q must end in .svg, .png, .hpgl or .plt", *output)
+    }
+
+    var( lastMod  time.Time
+         lastSize int64 = -1
+         lastErr  string
+    )
+    fmt.Fprintf(out, "watching %s every %v; press Ctrl-C to stop\n", grammarPath, *interval)
+    for {
+        info, err := os.Stat(grammarPath)
+        switch {
+            case err != nil:
+                if err.Error() != lastErr { fmt.Fprintf(out, "%s error: %v\n", timestamp(), err) }
+                lastErr, lastSize = err.Error(), -1
+            case !info.ModTime().Equal(lastMod) || info.Size() != lastSize:
+                lastMod, lastSize, lastErr = info.ModTime(), info.Size(), ""
+                fmt.Fprintf(out, "%s %s\n", timestamp(), rerender(grammarPath, *output, *width, *height, *limit))
+        }
+        time.Sleep(*interval)
+    }
+}
+func rerender(grammarPath, outputPath string, width, height, limit int) string {
+    //Loads, derives and renders the grammar, returning a one-line report.
+    start        := time.Now()
+    grammar, err := lsystems.LoadGrammar(grammarPath)
+    if err != nil { return "error: " + err.Error() }
+    if err = grammar.Generate(limit); err != nil { return "error: " + err.Error() }
+    var image []byte
+    switch strings.ToLower(filepath.Ext(outputPath)) {
+        case ".svg":
+            image, err = lsystems.RenderSvg(lsystems.TurtleCmds, grammar.ANGLE, width, height, 1., "black", "white",
+                                            lsystems.RenderOptions{})
+        case ".png":
+            image, err = lsystems.RenderPng(lsystems.TurtleCmds, grammar.ANGLE, width, height, 1, nil, lsystems.RenderOptions{})
+        default:
+            media          := lsystems.MediaA4
+            media.LANDSCAPE = width > height
+            title          := strings.TrimSuffix(filepath.Base(grammarPath), filepath.Ext(grammarPath))
+            image, err = lsystems.RenderHpgl(lsystems.TurtleCmds, grammar.ANGLE, title, 0.35, media, lsystems.RenderOptions{})
+    }
+    if err != nil { return "error: " + err.Error() }
+    //replace the output in one step, so that viewers never load a partial file
+    temporary := outputPath + ".tmp"
+    if err = os.WriteFile(temporary, image, 0644); err != nil { return "error: " + err.Error() }
+    if err = os.Rename(temporary, outputPath); err != nil { return "error: " + err.Error() }
+    return fmt.Sprintf("order %d: %d symbols rendered to %s in %v", grammar.ORDER, len(lsystems.TurtleCmds), outputPath,
+                       time.Since(start).Round(time.Millisecond))
+}
+func timestamp() string {
+    return time.Now().Format("15:04:05")
+}
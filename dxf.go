@@ -0,0 +1,233 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      DXF (AutoCAD Release 12 ASCII) output for importing L-systems into CAD tools.
+ *  Types:
+ *      DxfLayers
+ *          Criterion assigning entities to layers: DxfSingleLayer, DxfLayerByDepth, DxfLayerByColor or
+ *          DxfLayerByStrahler.
+ *      DxfSettings
+ *          Drawing settings for DXF output.
+ *  Functions:
+ *      DxfPlot(angle float64, settings DxfSettings, dxfPath string)
+ *          Converts the latest generated turtle commands to a DXF drawing in real-world units.
+ *      DxfPlotWriter(writer io.Writer, angle float64, settings DxfSettings)
+ *          Converts the latest generated turtle commands to a DXF drawing in real-world units written to an io.Writer.
+ *  Remarks:
+ *      Release 12 predates the LWPOLYLINE and HATCH entities, so continuous runs of the pen are written as POLYLINE
+ *      entities (or LINE entities on request) and filled polygons as SOLID triangles with a closed POLYLINE outline.
+ *      Each layer is given one of the AutoCAD Color Index colors 7, 1, 2, ..., 6 in turn.
+ *  History: v1.10.0 - October 18, 2026 - Original release.
+ *           v1.20.0 - October 18, 2026 - Added layer assignment by Horton-Strahler order.
+ *           v1.22.0 - October 18, 2026 - Added vertex welding.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "bytes"
+    "fmt"
+    "io"
+    "math"
+    "sort"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type DxfSettings struct {
+    STRIDE   float64   //length of a turtle stride in drawing units; ignored if WIDTH is set
+    WIDTH    float64   //width of the drawing in drawing units; 0 to scale by STRIDE
+    UNITS    string    //name of the drawing units, e.g., "mm", recorded as a comment since R12 has no units variable
+    LAYER_BY DxfLayers //criterion assigning the entities to layers
+    LINES    bool      //true to write LINE entities instead of POLYLINE entities
+}
+type DxfLayers int
+const(
+    DxfSingleLayer  DxfLayers = iota //all the entities on layer "LSYSTEM"
+    DxfLayerByDepth                  //one layer per branch depth, named "DEPTH_n"
+    DxfLayerByColor                  //one layer per color index, named "COLOR_n", as set with the ";" and "," symbols
+    DxfLayerByStrahler               //one layer per Horton-Strahler order, named "STRAHLER_n"
+)
+func DxfPlot(angle float64, settings DxfSettings, dxfPath string) {
+/*         Purpose : Converts the latest generated turtle commands to a DXF drawing in real-world units.
+ *       Arguments : angle    = production angle in degrees.
+ *                   settings = drawing settings.
+ *                   dxfPath  = file path for the drawing.
+ *         Returns : None.
+ * Externals -  In : TurtleCmds
+ * Externals - Out : None.
+ *       Functions : DxfPlotWriter, fileWrite, halt
+ *         Remarks : See DxfPlotWriter.
+ *         History : v1.10.0 - October 18, 2026 - Original release.
+ */
+    if dxfPath == "" { halt("the path for the drawing was not specified") }
+
+    var buffer bytes.Buffer
+    DxfPlotWriter(&buffer, angle, settings)
+    fileWrite(dxfPath, buffer.String())
+    return
+} //end func DxfPlot
+func DxfPlotWriter(writer io.Writer, angle float64, settings DxfSettings) {
+/*         Purpose : Converts the latest generated turtle commands to a DXF drawing in real-world units written to an
+ *                   io.Writer.
+ *       Arguments : writer   = destination of the drawing.
+ *                   angle    = production angle in degrees.
+ *                   settings = drawing settings.
+ *         Returns : None.
+ * Externals -  In : TurtleCmds, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : assignStrahler, chainSegments, halt, logo2Geometry, splitSegments, streamWrite,
+ *                   triangulatePolygon, weldGeometry
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { } ; ,
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - The drawing is translated so that its bounding box starts at the origin.
+ *         History : v1.10.0 - October 18, 2026 - Original release.
+ *                   v1.20.0 - October 18, 2026 - Added layer assignment by Horton-Strahler order.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ */
+    if TurtleCmds == ""         { halt("the turtle commands were not generated") }
+    if angle      == 0.         { halt("the production angle is zero") }
+    if writer     == nil        { halt("the writer for the drawing was not specified") }
+    if settings.WIDTH < 0.      { halt("the drawing width must be non-negative") }
+    if settings.WIDTH == 0. && !(settings.STRIDE > 0.) { halt("the stride length must be positive") }
+    if settings.LAYER_BY < DxfSingleLayer || settings.LAYER_BY > DxfLayerByStrahler { halt("the layer assignment is not valid") }
+
+    var( buffer   bytes.Buffer
+         entities bytes.Buffer
+         layers   = make(map[int]string)
+    )
+    //Convert the turtle commands to line segments and polygons using unit turtle strides
+    geometry := logo2Geometry(TurtleCmds, angle, nil)
+    if settings.LAYER_BY == DxfLayerByStrahler { assignStrahler(&geometry) }
+    weldGeometry(&geometry)
+    //Scale the drawing to real-world units
+    scale := settings.STRIDE
+    if settings.WIDTH > 0. {
+        scale = settings.WIDTH / math.Max(geometry.XMAX - geometry.XMIN, 1e-9)
+    }
+    fit := func(p _point) _point { return _point{scale * (p.X - geometry.XMIN), scale * (p.Y - geometry.YMIN)} }
+    //Name the layers
+    keyOf := func(depth, color, order int) int {
+        switch settings.LAYER_BY {
+            case DxfLayerByDepth:    return depth
+            case DxfLayerByColor:    return color
+            case DxfLayerByStrahler: return order
+        }
+        return 0
+    }
+    layerOf := func(key int) string {
+        if _, ok := layers[key]; !ok {
+            layers[key] = map[DxfLayers]string{DxfSingleLayer:     "LSYSTEM",
+                                               DxfLayerByDepth:    fmt.Sprintf("DEPTH_%d", key),
+                                               DxfLayerByColor:    fmt.Sprintf("COLOR_%d", key),
+                                               DxfLayerByStrahler: fmt.Sprintf("STRAHLER_%d", key)} [settings.LAYER_BY]
+        }
+        return layers[key]
+    }
+    coords := func(p _point, code int) string {
+        q := fit(p)
+        return fmt.Sprintf("%d\n%.6f\n%d\n%.6f\n%d\n0.0\n", code, q.X, code + 10, q.Y, code + 20)
+    }
+    polyline := func(layer string, vertices []_point, closed bool) {
+        fmt.Fprintf(&entities, "0\nPOLYLINE\n8\n%s\n66\n1\n10\n0.0\n20\n0.0\n30\n0.0\n70\n%d\n", layer,
+                    map[bool]int{true: 1, false: 0} [closed])
+        for _, vertex := range vertices {
+            fmt.Fprintf(&entities, "0\nVERTEX\n8\n%s\n%s", layer, coords(vertex, 10))
+        }
+        fmt.Fprintf(&entities, "0\nSEQEND\n8\n%s\n", layer)
+    }
+    //Compose the entities: polygons first, then the line segments
+    for _, polygon := range geometry.POLYGONS {
+        layer := layerOf(keyOf(polygon.DEPTH, polygon.COLOR, polygon.ORDER))
+        for _, triangle := range triangulatePolygon(polygon.VERTICES) {
+            fmt.Fprintf(&entities, "0\nSOLID\n8\n%s\n%s%s%s%s", layer, coords(triangle[0], 10), coords(triangle[1], 11),
+                        coords(triangle[2], 12), coords(triangle[2], 13))
+        }
+        polyline(layer, polygon.VERTICES, true)
+    }
+    for _, run := range splitSegments(geometry.SEGMENTS, keyOf, true) {
+        layer := layerOf(run.KEY)
+        if settings.LINES {
+            for _, segment := range run.SEGMENTS {
+                fmt.Fprintf(&entities, "0\nLINE\n8\n%s\n%s%s", layer, coords(segment.FROM, 10), coords(segment.TO, 11))
+            }
+            continue
+        }
+        for _, vertices := range chainSegments(run.SEGMENTS) {
+            polyline(layer, vertices, false)
+        }
+    }
+    //Compose the drawing: header, tables and entities
+    var keys []int
+    for key := range layers {
+        keys = append(keys, key)
+    }
+    sort.Ints(keys)
+    if settings.UNITS != "" { fmt.Fprintf(&buffer, "999\nDrawing units: %s\n", settings.UNITS) }
+    fmt.Fprintf(&buffer, "0\nSECTION\n2\nHEADER\n9\n$ACADVER\n1\nAC1009\n9\n$EXTMIN\n%s9\n$EXTMAX\n%s0\nENDSEC\n",
+                coords(_point{geometry.XMIN, geometry.YMIN}, 10), coords(_point{geometry.XMAX, geometry.YMAX}, 10))
+    fmt.Fprintf(&buffer, "0\nSECTION\n2\nTABLES\n" +
+                         "0\nTABLE\n2\nLTYPE\n70\n1\n" +
+                         "0\nLTYPE\n2\nCONTINUOUS\n70\n0\n3\nSolid line\n72\n65\n73\n0\n40\n0.0\n0\nENDTAB\n")
+    fmt.Fprintf(&buffer, "0\nTABLE\n2\nLAYER\n70\n%d\n", len(keys))
+    for k, key := range keys {
+        fmt.Fprintf(&buffer, "0\nLAYER\n2\n%s\n70\n0\n62\n%d\n6\nCONTINUOUS\n", layers[key], 1 + (k + 6) % 7)
+    }
+    fmt.Fprintf(&buffer, "0\nENDTAB\n0\nENDSEC\n")
+    fmt.Fprintf(&buffer, "0\nSECTION\n2\nENTITIES\n%s0\nENDSEC\n0\nEOF\n", entities.String())
+    //Output the drawing to the specified destination
+    streamWrite(writer, buffer.String())
+    return
+} //end func DxfPlotWriter
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+func triangulatePolygon(vertices []_point) (triangles [][3]_point) {
+/*         Purpose : Triangulates a simple polygon by ear clipping.
+ *       Arguments : vertices = polygon vertices in either orientation.
+ *         Returns : slice of triangles covering the polygon.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : Self-intersecting polygons, which have no ears left at some point, are completed as a fan.
+ *         History : v1.10.0 - October 18, 2026 - Original release.
+ */
+    var ring []_point
+    for k, vertex := range vertices { //drop repeated vertices, including a closing one
+        if k > 0 && vertex == vertices[k-1] { continue }
+        ring = append(ring, vertex)
+    }
+    if len(ring) > 1 && ring[0] == ring[len(ring)-1] { ring = ring[:len(ring)-1] }
+    if len(ring) < 3 { return }
+    cross := func(a, b, c _point) float64 { return (b.X - a.X) * (c.Y - a.Y) - (b.Y - a.Y) * (c.X - a.X) }
+    area  := 0.
+    for k := range ring {
+        area += cross(_point{0., 0.}, ring[k], ring[(k+1) % len(ring)])
+    }
+    if area < 0. { //make the orientation counterclockwise
+        for i, j := 0, len(ring) - 1; i < j; i, j = i + 1, j - 1 {
+            ring[i], ring[j] = ring[j], ring[i]
+        }
+    }
+    for len(ring) > 3 {
+        clipped := false
+        for k := range ring {
+            a, b, c := ring[(k + len(ring) - 1) % len(ring)], ring[k], ring[(k+1) % len(ring)]
+            if cross(a, b, c) <= 0. { continue } //reflex or flat corner
+            ear := true
+            for _, p := range ring {
+                if p == a || p == b || p == c { continue }
+                if cross(a, b, p) >= 0. && cross(b, c, p) >= 0. && cross(c, a, p) >= 0. { ear = false; break }
+            }
+            if !ear { continue }
+            triangles = append(triangles, [3]_point{a, b, c})
+            ring      = append(ring[:k], ring[k+1:]...)
+            clipped   = true
+            break
+        }
+        if !clipped { break }
+    }
+    for k := 1; k + 1 < len(ring); k++ { //remaining triangle, or fan of a degenerate remainder
+        triangles = append(triangles, [3]_point{ring[0], ring[k], ring[k+1]})
+    }
+    return
+} //end func triangulatePolygon
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of dxf.go
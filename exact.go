@@ -0,0 +1,152 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      exact turtle arithmetic for production angles dividing 360 degrees.
+ *  Variables:
+ *      ExactTurtle bool
+ *          Interprets the turtle commands exactly whenever the headings divide the full turn (default: false).
+ *  Remarks:
+ *      With N headings to a full turn, the turtle's heading is kept as an integer number of 360/N degree steps and its
+ *      position as integer coordinates over the powers of the N-th root of unity z = exp(2*pi*i/N), reduced modulo the
+ *      N-th cyclotomic polynomial. The reduced coordinates are unique, so that revisiting a position in any order of
+ *      moves yields the very same coordinates, and hence the very same floating-point vertex. N is the smallest
+ *      number of headings, up to 360, that is a whole multiple of the production angle, of 90 degrees if "$" is used,
+ *      of 180 degrees if "|" is used, and of every heading set with "(...)". Otherwise the turtle falls back to
+ *      floating-point arithmetic.
+ *  History: v1.21.0 - October 18, 2026 - Original release.
+ *           v1.24.0 - October 18, 2026 - Read the headings from module arrays.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "bytes"
+    "math"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+var ExactTurtle bool //interpret the turtle commands exactly whenever the headings divide the full turn
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+const _maxLatticeHeadings = 360 //most headings to a full turn supported by the exact turtle
+type _lattice struct {
+    HEADINGS int       //number of headings to a full turn
+    TURN     int       //headings per production angle
+    UNITS    [][]int64 //reduced coordinates of the unit vector of each heading
+    COS      []float64 //x ordinates of the powers of the root of unity spanning the coordinates
+    SIN      []float64 //y ordinates of the powers of the root of unity spanning the coordinates
+}
+func makeLattice(modules *_modules, angle float64, exact bool) *_lattice {
+/*         Purpose : Sets up the exact turtle arithmetic for turtle commands.
+ *       Arguments : modules = turtle commands as a module array.
+ *                   angle   = production angle in degrees.
+ *                   exact   = true for the exact turtle, e.g., ExactTurtle.
+ *         Returns : the lattice, or nil if exact is false or the headings do not divide the full turn.
+ * Externals -  In : _maxLatticeHeadings
+ * Externals - Out : None.
+ *       Functions : cyclotomicPolynomial
+ *         Remarks : None.
+ *         History : v1.21.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Collected the headings from the module array.
+ */
+    if !exact || angle == 0. { return nil }
+    //Collect the headings that must be whole numbers of steps
+    headings := append([]float64{angle}, modules.SLAB...)
+    if bytes.IndexByte(modules.SYMBOLS, '$') >= 0 { headings = append(headings, 90.) }
+    if bytes.IndexByte(modules.SYMBOLS, '|') >= 0 { headings = append(headings, 180.) }
+    //Find the smallest number of headings
+    var lattice _lattice
+    steps := func(heading float64, n int) (int, bool) {
+        s := heading * float64(n) / 360.
+        return int(math.Round(s)), math.Abs(s - math.Round(s)) < 1e-9 * math.Max(1., math.Abs(s))
+    }
+    for n := 1; n <= _maxLatticeHeadings && lattice.HEADINGS == 0; n++ {
+        whole := true
+        for _, heading := range headings {
+            if _, ok := steps(heading, n); !ok { whole = false; break }
+        }
+        if whole { lattice.HEADINGS = n }
+    }
+    if lattice.HEADINGS == 0 { return nil }
+    lattice.TURN, _ = steps(angle, lattice.HEADINGS)
+    //Reduce the powers of the root of unity modulo the cyclotomic polynomial
+    n       := lattice.HEADINGS
+    phi     := cyclotomicPolynomial(n)
+    degree  := len(phi) - 1
+    power   := make([]int64, degree)
+    power[0] = 1
+    for k := 0; k < n; k++ {
+        lattice.UNITS = append(lattice.UNITS, append([]int64(nil), power...))
+        //multiply by z, replacing z^degree by minus the lower terms of the monic polynomial
+        top := power[degree-1]
+        copy(power[1:], power[:degree-1])
+        power[0] = 0
+        for j := 0; j < degree; j++ {
+            power[j] -= top * phi[j]
+        }
+    }
+    //Locate the powers spanning the coordinates, exactly along the axes
+    for j := 0; j < degree; j++ {
+        switch 4 * j % n {
+            case 0:
+                cos, sin := map[int]float64{0: 1., 1: 0., 2: -1., 3: 0.}, map[int]float64{0: 0., 1: 1., 2: 0., 3: -1.}
+                quarter  := 4 * j / n
+                lattice.COS, lattice.SIN = append(lattice.COS, cos[quarter]), append(lattice.SIN, sin[quarter])
+            default:
+                radians := 2. * math.Pi * float64(j) / float64(n)
+                lattice.COS, lattice.SIN = append(lattice.COS, math.Cos(radians)), append(lattice.SIN, math.Sin(radians))
+        }
+    }
+    return &lattice
+} //end func makeLattice
+func(lattice *_lattice) heading(degrees float64) int {
+    steps := int(math.Round(degrees * float64(lattice.HEADINGS) / 360.))
+    return ((steps % lattice.HEADINGS) + lattice.HEADINGS) % lattice.HEADINGS
+} //end func heading
+func(lattice *_lattice) turn(steps, delta int) int {
+    return (((steps + delta) % lattice.HEADINGS) + lattice.HEADINGS) % lattice.HEADINGS
+} //end func turn
+func(lattice *_lattice) forward(coords []int64, steps int) []int64 {
+    //a new slice, so that the coordinates saved on the turtle stack are left untouched
+    unit := lattice.UNITS[steps]
+    next := make([]int64, len(unit))
+    for j := range unit {
+        if coords != nil { next[j] = coords[j] }
+        next[j] += unit[j]
+    }
+    return next
+} //end func forward
+func(lattice *_lattice) point(coords []int64) (x, y float64) {
+    for j, c := range coords {
+        x += float64(c) * lattice.COS[j]
+        y += float64(c) * lattice.SIN[j]
+    }
+    return
+} //end func point
+func cyclotomicPolynomial(n int) []int64 {
+/*         Purpose : Computes the n-th cyclotomic polynomial.
+ *       Arguments : n = positive order.
+ *         Returns : the integer coefficients in increasing degree.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : cyclotomicPolynomial
+ *         Remarks : x^n - 1 is divided by the cyclotomic polynomials of the proper divisors of n, all monic, so that the
+ *                   division is exact over the integers.
+ *         History : v1.21.0 - October 18, 2026 - Original release.
+ */
+    poly := make([]int64, n + 1) //x^n - 1
+    poly[0], poly[n] = -1, 1
+    for d := 1; d < n; d++ {
+        if n % d != 0 { continue }
+        divisor  := cyclotomicPolynomial(d)
+        quotient := make([]int64, len(poly) - len(divisor) + 1)
+        for k := len(quotient) - 1; k >= 0; k-- { //long division by a monic polynomial
+            quotient[k] = poly[k + len(divisor) - 1]
+            for j := range divisor {
+                poly[k + j] -= quotient[k] * divisor[j]
+            }
+        }
+        poly = quotient
+    }
+    return poly
+} //end func cyclotomicPolynomial
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of exact.go
@@ -33,6 +33,7 @@ func main() {
         turtleCmds[run]   = lsystems.TurtleCmds
         turtleAngles[run] = angle
     }
+    lsystems.MultiPlotScaling = lsystems.SharedIsometric //keep the plant sizes comparable
     /*Output PNG file*/
     lsystems.MultiPlot(turtleCmds, turtleAngles, terminalCmd, outputCmd, plotTitle, labels, lineColor)
     fmt.Println("output written to " + pngFile)
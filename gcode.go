@@ -0,0 +1,201 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      G-code output for GRBL-based pen plotters, laser cutters and CNC machines.
+ *  Types:
+ *      GcodeMachine
+ *          Machine settings for G-code output.
+ *      GcodeOrigin
+ *          Placement of the machine origin within the work area: OriginBottomLeft, OriginTopLeft or OriginCenter.
+ *  Functions:
+ *      PenPlotterMachine(width, height float64) GcodeMachine
+ *          Returns the settings for a servo pen plotter with the given work area in millimeters.
+ *      LaserMachine(width, height, power float64) GcodeMachine
+ *          Returns the settings for a laser cutter with the given work area in millimeters and laser power.
+ *      GcodePlot(angle float64, machine GcodeMachine, gcodePath string)
+ *          Converts the latest generated turtle commands to a G-code program. The resulting plot will be isometrically
+ *          scaled and centered in the work area.
+ *      GcodePlotWriter(writer io.Writer, angle float64, machine GcodeMachine)
+ *          Converts the latest generated turtle commands to a G-code program written to an io.Writer. The resulting
+ *          plot will be isometrically scaled and centered in the work area.
+ *  Remarks:
+ *      The programs use millimeters and absolute coordinates (G21 G90). Moves with the pen up are rapid (G0) unless a
+ *      travel feed rate is set; moves with the pen down are linear (G1) at the drawing feed rate.
+ *  History: v1.9.0 - October 18, 2026 - Original release.
+ *           v1.22.0 - October 18, 2026 - Added vertex welding.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "bytes"
+    "fmt"
+    "io"
+    "math"
+    "strings"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type GcodeMachine struct {
+    WIDTH       float64     //width of the work area in millimeters, along the X axis
+    HEIGHT      float64     //height of the work area in millimeters, along the Y axis
+    MARGIN      float64     //blank border in millimeters
+    ORIGIN      GcodeOrigin //placement of the machine origin within the work area
+    PEN_UP      string      //commands raising the pen or turning the tool off, one per line
+    PEN_DOWN    string      //commands lowering the pen or turning the tool on, one per line
+    DWELL       float64     //pause in seconds after raising or lowering the pen; 0 for none
+    FEED        float64     //feed rate in millimeters per minute of the moves with the pen down
+    TRAVEL_FEED float64     //feed rate in millimeters per minute of the moves with the pen up; 0 for rapid moves
+}
+type GcodeOrigin int
+const(
+    OriginBottomLeft GcodeOrigin = iota //X and Y increase rightwards and upwards from the bottom left corner
+    OriginTopLeft                       //X increases rightwards and Y decreases downwards from the top left corner
+    OriginCenter                        //X and Y are measured from the center of the work area
+)
+func PenPlotterMachine(width, height float64) GcodeMachine {
+/*         Purpose : Returns the settings for a servo pen plotter with the given work area in millimeters.
+ *       Arguments : width  = width of the work area in millimeters.
+ *                   height = height of the work area in millimeters.
+ *         Returns : machine settings, with the origin at the bottom left corner and 5 mm margins.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : The pen servo is driven through the spindle commands, as in most GRBL plotter firmwares.
+ *         History : v1.9.0 - October 18, 2026 - Original release.
+ */
+    return GcodeMachine{WIDTH: width, HEIGHT: height, MARGIN: 5., PEN_UP: "M3 S0", PEN_DOWN: "M3 S1000", DWELL: 0.15,
+                        FEED: 3000.}
+} //end func PenPlotterMachine
+func LaserMachine(width, height, power float64) GcodeMachine {
+/*         Purpose : Returns the settings for a laser cutter with the given work area in millimeters and laser power.
+ *       Arguments : width  = width of the work area in millimeters.
+ *                   height = height of the work area in millimeters.
+ *                   power  = laser power in spindle units, e.g., 0 to 1000 for GRBL's default $30.
+ *         Returns : machine settings, with the origin at the bottom left corner and 5 mm margins.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : The laser is switched on with M4 (dynamic power) and off with M5.
+ *         History : v1.9.0 - October 18, 2026 - Original release.
+ */
+    return GcodeMachine{WIDTH: width, HEIGHT: height, MARGIN: 5., PEN_UP: "M5", PEN_DOWN: fmt.Sprintf("M4 S%g", power),
+                        FEED: 1000.}
+} //end func LaserMachine
+func GcodePlot(angle float64, machine GcodeMachine, gcodePath string) {
+/*         Purpose : Converts the latest generated turtle commands to a G-code program. The resulting plot will be
+ *                   isometrically scaled and centered in the work area.
+ *       Arguments : angle     = production angle in degrees.
+ *                   machine   = machine settings.
+ *                   gcodePath = file path or device port for the G-code program.
+ *         Returns : None.
+ * Externals -  In : OptimizePenTravel, TurtleCmds
+ * Externals - Out : PenTravel
+ *       Functions : fileWrite, GcodePlotWriter, halt
+ *         Remarks : See GcodePlotWriter.
+ *         History : v1.9.0 - October 18, 2026 - Original release.
+ */
+    if gcodePath == "" { halt("the path for the plot was not specified") }
+
+    var buffer bytes.Buffer
+    GcodePlotWriter(&buffer, angle, machine)
+    fileWrite(gcodePath, buffer.String())
+    return
+} //end func GcodePlot
+func GcodePlotWriter(writer io.Writer, angle float64, machine GcodeMachine) {
+/*         Purpose : Converts the latest generated turtle commands to a G-code program written to an io.Writer. The
+ *                   resulting plot will be isometrically scaled and centered in the work area.
+ *       Arguments : writer  = destination of the G-code program.
+ *                   angle   = production angle in degrees.
+ *                   machine = machine settings.
+ *         Returns : None.
+ * Externals -  In : OptimizePenTravel, TurtleCmds, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : PenTravel
+ *       Functions : chainSegments, halt, logo2Geometry, makeFit2Box, optimizeTravel, resetPenTravel,
+ *                   streamWrite, validMachine, weldGeometry
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - Polygons are outlined, as G-code has no fill primitive.
+ *                   - If OptimizePenTravel is set, the strokes are merged and reordered to minimise the pen-up travel.
+ *                   - The program ends with the pen up at the origin.
+ *         History : v1.9.0 - October 18, 2026 - Original release.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ */
+    if TurtleCmds == "" { halt("the turtle commands were not generated") }
+    if angle      == 0. { halt("the production angle is zero") }
+    if writer     == nil { halt("the writer for the plot was not specified") }
+    validMachine(machine)
+
+    var( buffer    bytes.Buffer
+         polylines [][]_point
+    )
+    //Convert the turtle commands to line segments and polygons using unit turtle strides
+    geometry := logo2Geometry(TurtleCmds, angle, nil)
+    weldGeometry(&geometry)
+    //Fit the drawing to the work area, in machine coordinates
+    xShift, yShift := 0., 0.
+    switch machine.ORIGIN {
+        case OriginTopLeft: yShift = -machine.HEIGHT
+        case OriginCenter:  xShift, yShift = -0.5 * machine.WIDTH, -0.5 * machine.HEIGHT
+    }
+    box := _box{machine.MARGIN + xShift, machine.MARGIN + yShift,
+                machine.WIDTH - 2. * machine.MARGIN, machine.HEIGHT - 2. * machine.MARGIN}
+    fit := makeFit2Box(geometry.XMIN, geometry.XMAX, geometry.YMIN, geometry.YMAX, box, true)
+    //Collect the strokes: polygon outlines first, then the line segments
+    for _, polygon := range geometry.POLYGONS {
+        polylines = append(polylines, append(append([]_point{}, polygon.VERTICES...), polygon.VERTICES[0]))
+    }
+    if report := resetPenTravel(); report != nil {
+        polylines = append(polylines, optimizeTravel(geometry.SEGMENTS, report)...)
+    } else {
+        polylines = append(polylines, chainSegments(geometry.SEGMENTS)...)
+    }
+    //Compose the G-code program
+    penUp   := strings.TrimSpace(machine.PEN_UP) + "\n"
+    penDown := strings.TrimSpace(machine.PEN_DOWN) + "\n"
+    if machine.DWELL > 0. {
+        penUp   += fmt.Sprintf("G4 P%.3f\n", machine.DWELL)
+        penDown += fmt.Sprintf("G4 P%.3f\n", machine.DWELL)
+    }
+    travel := func(p _point) string {
+        if machine.TRAVEL_FEED > 0. { return fmt.Sprintf("G1 X%.3f Y%.3f F%.0f\n", p.X, p.Y, machine.TRAVEL_FEED) }
+        return fmt.Sprintf("G0 X%.3f Y%.3f\n", p.X, p.Y)
+    }
+    fmt.Fprintf(&buffer, "; L-system plot - %d strokes\n", len(polylines))
+    //set millimeters and absolute coordinates, then raise the pen
+    fmt.Fprintf(&buffer, "G21\nG90\n%s", penUp)
+    for _, polyline := range polylines {
+        fmt.Fprintf(&buffer, "%s%s", travel(fit(polyline[0])), penDown)
+        for k, vertex := range polyline[1:] {
+            p := fit(vertex)
+            if k == 0 {
+                fmt.Fprintf(&buffer, "G1 X%.3f Y%.3f F%.0f\n", p.X, p.Y, machine.FEED)
+            } else {
+                fmt.Fprintf(&buffer, "G1 X%.3f Y%.3f\n", p.X, p.Y)
+            }
+        }
+        buffer.WriteString(penUp)
+    }
+    //return to the origin and end the program
+    fmt.Fprintf(&buffer, "%sM2\n", travel(_point{0., 0.}))
+    //Output the program to the specified destination
+    streamWrite(writer, buffer.String())
+    return
+} //end func GcodePlotWriter
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+func validMachine(machine GcodeMachine) {
+    if !(machine.WIDTH > 0.) || !(machine.HEIGHT > 0.) { halt("the work area must be positive") }
+    if machine.MARGIN < 0. || 2. * machine.MARGIN >= math.Min(machine.WIDTH, machine.HEIGHT) {
+        halt("the work area margin is not valid")
+    }
+    if machine.ORIGIN < OriginBottomLeft || machine.ORIGIN > OriginCenter { halt("the origin placement is not valid") }
+    if strings.TrimSpace(machine.PEN_UP) == "" || strings.TrimSpace(machine.PEN_DOWN) == "" {
+        halt("the pen-up and pen-down commands were not specified")
+    }
+    if machine.DWELL < 0.       { halt("the dwell time must be non-negative") }
+    if !(machine.FEED > 0.)     { halt("the feed rate must be positive") }
+    if machine.TRAVEL_FEED < 0. { halt("the travel feed rate must be non-negative") }
+    return
+} //end func validMachine
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of gcode.go
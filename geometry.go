@@ -0,0 +1,239 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      device-independent turtle geometry shared by the pure-Go renderers.
+ *  Remarks:
+ *      The turtle interpretation mirrors that of makeLogo2Gnuplot: unit strides, a default heading of 0 degrees and the
+ *      constants F f + - | $ ( ) [ ] { }. In addition, ";" and "," increment and decrement a color index as in
+ *      L-studio. All other symbols are ignored.
+ *  History: v1.1.0 - October 18, 2026 - Original release.
+ *           v1.2.0 - October 18, 2026 - Added the chaining of segments into polylines.
+ *           v1.3.0 - October 18, 2026 - Added variable stride lengths.
+ *           v1.7.0 - October 18, 2026 - Added color indices.
+ *           v1.10.0 - October 18, 2026 - Added the splitting of segments into runs.
+ *           v1.19.0 - October 18, 2026 - Added the recording of the branch brackets.
+ *           v1.20.0 - October 18, 2026 - Added Horton-Strahler keys.
+ *           v1.21.0 - October 18, 2026 - Added the exact turtle.
+ *           v1.24.0 - October 18, 2026 - Interpreted the turtle commands as module arrays.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "math"
+    "sort"
+)
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+type _point struct {
+    X float64 //x ordinate
+    Y float64 //y ordinate
+}
+type _segment struct {
+    FROM  _point //start of the line segment
+    TO    _point //end of the line segment
+    DEPTH int    //branch depth, i.e., the number of unmatched "[" when the segment was drawn
+    COLOR int    //color index when the segment was drawn
+    ORDER int    //Horton-Strahler order of the segment's branch, or 0 if not assigned
+}
+type _polygon struct {
+    VERTICES []_point //polygon vertices in turtle order
+    AFTER    int      //number of line segments drawn before the polygon was closed
+    DEPTH    int      //branch depth when the polygon was closed
+    COLOR    int      //color index when the polygon was closed
+    ORDER    int      //Horton-Strahler order, or 0 if not assigned
+}
+type _bracket struct {
+    OPEN  bool //true for "[", false for "]"
+    AFTER int  //number of line segments drawn before the bracket
+}
+type _segmentRun struct {
+    KEY      int       //pen, layer or color shared by the segments
+    SEGMENTS []_segment //line segments in turtle order
+}
+type _geometry struct {
+    SEGMENTS []_segment //drawn line segments in turtle order
+    POLYGONS []_polygon //filled polygons in turtle order
+    BRACKETS []_bracket //branch brackets in turtle order
+    XMIN     float64    //bounding box of all turtle positions
+    XMAX     float64
+    YMIN     float64
+    YMAX     float64
+}
+////Geometry operations
+func logo2Geometry(turtleCmds string, angle float64, strides []float64) (geometry _geometry) {
+/*         Purpose : Interprets turtle commands as line segments and polygons.
+ *       Arguments : turtleCmds = turtle commands.
+ *                   angle      = production angle in degrees.
+ *                   strides    = stride length of each successive "F" or "f", or nil for unit turtle strides.
+ *         Returns : the resulting geometry.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : halt, modules2Geometry, parseModules
+ *         Remarks : See modules2Geometry.
+ *         History : v1.1.0 - October 18, 2026 - Original release.
+ *                   v1.3.0 - October 18, 2026 - Added variable stride lengths.
+ *                   v1.7.0 - October 18, 2026 - Added color indices.
+ *                   v1.19.0 - October 18, 2026 - Added the recording of the branch brackets.
+ *                   v1.21.0 - October 18, 2026 - Added the exact turtle.
+ *                   v1.24.0 - October 18, 2026 - Interpreted the commands as a module array.
+ */
+    modules, err := parseModules(turtleCmds)
+    if err != nil { halt(err.Error()) }
+    return modules2Geometry(&modules, angle, strides, ExactTurtle, ProgressBars)
+} //end func logo2Geometry
+func modules2Geometry(modules *_modules, angle float64, strides []float64, exact, progress bool) (geometry _geometry) {
+/*         Purpose : Interprets a module array as line segments and polygons.
+ *       Arguments : modules  = turtle commands as a module array.
+ *                   angle    = production angle in degrees.
+ *                   strides  = stride length of each successive "F" or "f", or nil for unit turtle strides.
+ *                   exact    = true for the exact turtle.
+ *                   progress = true to display a progress bar according to ProgressBars.
+ *         Returns : the resulting geometry.
+ * Externals -  In : _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : makeLattice, updateProgressBar
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { } ; ,
+ *                     All other symbols will be ignored.
+ *                   - Inside polygon mode, both "F" and "f" add vertices to the polygon instead of drawing segments.
+ *                   - The color index starts at 0 and is saved and restored along with the turtle's status.
+ *                   - The exact turtle applies to unit turtle strides only.
+ *         History : v1.24.0 - October 18, 2026 - Original release, split from logo2Geometry.
+ */
+    var( color   int
+         colors  []int
+         param   int //next parameter in the slab
+         polygon []_point
+         stack   _turtleHistory
+         stride  = 1.
+         strideK = 0
+         turtle  _turtleStatus
+    )
+    //Initialize
+    var lattice *_lattice
+    if strides == nil { lattice = makeLattice(modules, angle, exact) }
+    //Convert the modules to line segments and polygons
+    for k, id := range modules.SYMBOLS {
+        if progress { updateProgressBar("logo -> geometry", k, len(modules.SYMBOLS)-1) }
+        symbol := string(id)
+        switch symbol {
+            case "F", "f": //draw or move forward
+                from := _point{turtle.X, turtle.Y}
+                if strides != nil {
+                    stride, strideK = strides[strideK], strideK + 1
+                }
+                if lattice != nil { //exact turtle
+                    turtle.COORDS      = lattice.forward(turtle.COORDS, turtle.STEPS)
+                    turtle.X, turtle.Y = lattice.point(turtle.COORDS)
+                } else {
+                    switch math.Mod(turtle.HEADING, 360.) {
+                        case 0.:
+                            turtle.X += stride
+                        case 90., -270.:
+                            turtle.Y += stride
+                        case 180., -180.:
+                            turtle.X -= stride
+                        case 270., -90.:
+                            turtle.Y -= stride
+                        default:
+                            radians   := turtle.HEADING * _degs2rads
+                            turtle.X  += stride * math.Cos(radians)
+                            turtle.Y  += stride * math.Sin(radians)
+                    }
+                }
+                geometry.XMIN, geometry.XMAX = math.Min(geometry.XMIN, turtle.X), math.Max(geometry.XMAX, turtle.X)
+                geometry.YMIN, geometry.YMAX = math.Min(geometry.YMIN, turtle.Y), math.Max(geometry.YMAX, turtle.Y)
+                switch {
+                    case polygon != nil: //continue with polygon
+                        polygon = append(polygon, _point{turtle.X, turtle.Y})
+                    case symbol == "F": //draw line segment
+                        geometry.SEGMENTS = append(geometry.SEGMENTS,
+                                                   _segment{from, _point{turtle.X, turtle.Y}, len(stack), color, 0})
+                }
+            case "+": //turn left
+                turtle.HEADING += angle
+                if lattice != nil { turtle.STEPS = lattice.turn(turtle.STEPS, lattice.TURN) }
+            case "-": //turn right
+                turtle.HEADING -= angle
+                if lattice != nil { turtle.STEPS = lattice.turn(turtle.STEPS, -lattice.TURN) }
+            case "|": //turn away
+                turtle.HEADING += 180.
+                if lattice != nil { turtle.STEPS = lattice.turn(turtle.STEPS, lattice.HEADINGS / 2) }
+            case "$": //head due north
+                turtle.HEADING = 90.
+                if lattice != nil { turtle.STEPS = lattice.heading(90.) }
+            case "(": //set arbitrary heading
+                turtle.HEADING, param = modules.SLAB[param], param + 1
+                if lattice != nil { turtle.STEPS = lattice.heading(turtle.HEADING) }
+            case "[": //store status
+                stack.push(turtle)
+                colors = append(colors, color)
+                geometry.BRACKETS = append(geometry.BRACKETS, _bracket{true, len(geometry.SEGMENTS)})
+            case "]": //restore status
+                turtle = stack.pop()
+                color, colors = colors[len(colors)-1], colors[:len(colors)-1]
+                geometry.BRACKETS = append(geometry.BRACKETS, _bracket{false, len(geometry.SEGMENTS)})
+            case ";": //increment color index
+                color++
+            case ",": //decrement color index
+                color--
+            case "{": //start polygon mode
+                polygon = []_point{{turtle.X, turtle.Y}}
+            case "}": //end polygon mode
+                if len(polygon) > 2 {
+                    geometry.POLYGONS = append(geometry.POLYGONS, _polygon{polygon, len(geometry.SEGMENTS),
+                                                                                len(stack), color, 0})
+                }
+                polygon = nil
+        }
+    }
+    return
+} //end func modules2Geometry
+func chainSegments(segments []_segment) (polylines [][]_point) {
+/*         Purpose : Chains consecutive line segments into polylines, that is, into continuous runs of the pen.
+ *       Arguments : segments = line segments in turtle order.
+ *         Returns : slice of polylines, each having at least two vertices.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : A new polyline starts whenever a segment does not begin where the previous one ended.
+ *         History : v1.2.0 - October 18, 2026 - Original release.
+ */
+    for k, segment := range segments {
+        if k == 0 || segment.FROM != segments[k-1].TO {
+            polylines = append(polylines, []_point{segment.FROM})
+        }
+        polylines[len(polylines)-1] = append(polylines[len(polylines)-1], segment.TO)
+    }
+    return
+} //end func chainSegments
+func splitSegments(segments []_segment, keyOf func(depth, color, order int) int, grouped bool) (runs []_segmentRun) {
+/*         Purpose : Splits line segments into runs sharing the same pen, layer or color.
+ *       Arguments : segments = line segments in turtle order.
+ *                   keyOf    = function computing the key of a segment from its branch depth, color index and
+ *                              Horton-Strahler order.
+ *                   grouped  = true to gather all the segments of a key into a single run.
+ *         Returns : slice of runs, in turtle order or, if grouped, in increasing key order.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : None.
+ *         History : v1.10.0 - October 18, 2026 - Original release.
+ *                   v1.20.0 - October 18, 2026 - Added the Horton-Strahler order to the keys.
+ */
+    for _, segment := range segments {
+        key := keyOf(segment.DEPTH, segment.COLOR, segment.ORDER)
+        k   := len(runs) - 1
+        if grouped {
+            for k = 0; k < len(runs) && runs[k].KEY != key; k++ {}
+        }
+        if k < 0 || k == len(runs) || runs[k].KEY != key {
+            runs = append(runs, _segmentRun{key, nil})
+            k    = len(runs) - 1
+        }
+        runs[k].SEGMENTS = append(runs[k].SEGMENTS, segment)
+    }
+    if grouped { sort.SliceStable(runs, func(i, j int) bool { return runs[i].KEY < runs[j].KEY }) }
+    return
+} //end func splitSegments
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of geometry.go
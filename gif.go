@@ -0,0 +1,147 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      animated GIF output of L-systems without external tools.
+ *  Functions:
+ *      GifMultiFrame(turtleCmds []string, turtleAngles []float64, width, height, lineWidth int, commonFit bool,
+ *                    delay int, palette color.Palette, gifPath string)
+ *          Renders a set of turtle commands as the successive frames of an animated GIF. The frames are isometrically
+ *          scaled and centered, either to a common bounding box or individually.
+ *      GifOrders(order int, axiom string, rules *strings.Replacer, angle float64, width, height, lineWidth int,
+ *                commonFit bool, delay int, palette color.Palette, gifPath string)
+ *          Renders the successive derivation orders 0 to "order" of the specified deterministic and context-free
+ *          production parameters as an animated GIF.
+ *  History: v1.1.0 - October 18, 2026 - Original release.
+ *           v1.22.0 - October 18, 2026 - Added vertex welding.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "bytes"
+    "image"
+    "image/color"
+    "image/gif"
+    "math"
+    "strings"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+func GifMultiFrame(turtleCmds []string, turtleAngles []float64, width, height, lineWidth int, commonFit bool,
+                   delay int, palette color.Palette, gifPath string) {
+/*         Purpose : Renders a set of turtle commands as the successive frames of an animated GIF. The frames are
+ *                   isometrically scaled and centered, either to a common bounding box or individually.
+ *       Arguments : turtleCmds   = slice of turtle commands, one per frame.
+ *                   turtleAngles = slice of production angles in degrees.
+ *                   width        = frame width in pixels.
+ *                   height       = frame height in pixels.
+ *                   lineWidth    = line width in pixels.
+ *                   commonFit    = true to scale all frames to their common bounding box, false to fit each frame.
+ *                   delay        = delay between frames in hundredths of a second.
+ *                   palette      = frame colors: index 0 is the background, the others are cycled by branch depth.
+ *                                  A nil palette selects black lines on a white background.
+ *                   gifPath      = file path for the animation.
+ *         Returns : None.
+ * Externals -  In : _defaultPalette, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : geometries2Gif, halt, logo2Geometry
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - The animation loops forever.
+ *         History : v1.1.0 - October 18, 2026 - Original release.
+ */
+    if len(turtleCmds)   == 0 { halt("the turtle commands were not specified") }
+    if len(turtleAngles) == 0 { halt("the turtle angles were not stated") }
+    if len(turtleAngles) < len(turtleCmds) {
+        halt("fewer turtle angles specified than the number of commands")
+    }
+    if width < 1 || height < 1 { halt("the frame size must be positive") }
+    if lineWidth < 1           { halt("the line width must be positive") }
+    if delay < 0               { halt("the frame delay must be non-negative") }
+    if palette == nil          { palette = _defaultPalette }
+    if len(palette) < 2 || len(palette) > 256 { halt("the palette must have between 2 and 256 colors") }
+    if gifPath == ""           { halt("the path for the animation was not specified") }
+
+    var geometries = make([]_geometry, len(turtleCmds))
+    //Interpret the turtle commands
+    for k, v := range turtleCmds {
+        if turtleAngles[k] == 0. { halt("the production angle is zero") }
+        geometries[k] = logo2Geometry(v, turtleAngles[k], nil)
+    }
+    //Render and output the animation
+    geometries2Gif(geometries, width, height, lineWidth, commonFit, delay, palette, gifPath)
+    return
+} //end func GifMultiFrame
+func GifOrders(order int, axiom string, rules *strings.Replacer, angle float64, width, height, lineWidth int,
+               commonFit bool, delay int, palette color.Palette, gifPath string) {
+/*         Purpose : Renders the successive derivation orders 0 to "order" of the specified deterministic and
+ *                   context-free production parameters as an animated GIF.
+ *       Arguments : order     = highest order of the curve, that is, the derivation length of the production rules.
+ *                   axiom     = production axiom.
+ *                   rules     = production rules.
+ *                   angle     = production angle in degrees.
+ *                   width     = frame width in pixels.
+ *                   height    = frame height in pixels.
+ *                   lineWidth = line width in pixels.
+ *                   commonFit = true to scale all frames to their common bounding box, false to fit each frame.
+ *                   delay     = delay between frames in hundredths of a second.
+ *                   palette   = frame colors: index 0 is the background, the others are cycled by branch depth.
+ *                               A nil palette selects black lines on a white background.
+ *                   gifPath   = file path for the animation.
+ *         Returns : None.
+ * Externals -  In : None.
+ * Externals - Out : TurtleCmds
+ *       Functions : Deterministic, GifMultiFrame, halt
+ *         Remarks : On return, TurtleCmds holds the commands of the highest order.
+ *         History : v1.1.0 - October 18, 2026 - Original release.
+ */
+    if order < 0 { halt("curve order must be non-negative") }
+
+    var( turtleCmds   []string
+         turtleAngles []float64
+    )
+    //Derive each order in turn
+    Deterministic(0, axiom, rules)
+    for n := 0; n <= order; n++ {
+        if n > 0 { TurtleCmds = rules.Replace(TurtleCmds) }
+        turtleCmds   = append(turtleCmds, TurtleCmds)
+        turtleAngles = append(turtleAngles, angle)
+    }
+    //Render the animation
+    GifMultiFrame(turtleCmds, turtleAngles, width, height, lineWidth, commonFit, delay, palette, gifPath)
+    return
+} //end func GifOrders
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+func geometries2Gif(geometries []_geometry, width, height, lineWidth int, commonFit bool, delay int,
+                    palette color.Palette, gifPath string) {
+    const margin = 4 //pixels
+    var(  animation  gif.GIF
+          xMin, xMax = math.Inf(1), math.Inf(-1)
+          yMin, yMax = math.Inf(1), math.Inf(-1)
+    )
+    //Find the common bounding box
+    for _, v := range geometries {
+        xMin, xMax = math.Min(xMin, v.XMIN), math.Max(xMax, v.XMAX)
+        yMin, yMax = math.Min(yMin, v.YMIN), math.Max(yMax, v.YMAX)
+    }
+    //Render the frames
+    for k := range geometries {
+        fit := makeFit2Canvas(xMin, xMax, yMin, yMax, width, height, margin)
+        if !commonFit {
+            fit = makeFit2Canvas(geometries[k].XMIN, geometries[k].XMAX, geometries[k].YMIN, geometries[k].YMAX,
+                                 width, height, margin)
+        }
+        frame := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+        weldGeometry(&geometries[k])
+        drawGeometry(frame, &geometries[k], fit, lineWidth, -1)
+        animation.Image = append(animation.Image, frame)
+        animation.Delay = append(animation.Delay, delay)
+    }
+    //Output the animation to the specified destination
+    var buffer bytes.Buffer
+    if err := gif.EncodeAll(&buffer, &animation); err != nil { halt("gif.EncodeAll - " + err.Error()) }
+    fileWrite(gifPath, buffer.String())
+    return
+} //end func geometries2Gif
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of gif.go
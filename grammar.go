@@ -0,0 +1,301 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      text grammar files for the Deterministic, Stochastic and HogewegHesper engines.
+ *  Types:
+ *      Grammar
+ *          Production parameters of an L-system for one of the package's engines.
+ *  Functions:
+ *      LoadGrammar(grammarPath string) (Grammar, error)
+ *          Reads a grammar from a text file.
+ *      ParseGrammar(reader io.Reader) (Grammar, error)
+ *          Reads a grammar from an io.Reader.
+ *  Methods:
+ *      (grammar Grammar) Validate() error
+ *          Checks that the grammar can be derived without halting.
+ *      (grammar Grammar) Step(turtleCmds string, maxSymbols int) (string, error)
+ *          Applies one derivation step of the grammar's engine to the given turtle commands.
+ *      (grammar Grammar) Generate(maxSymbols int) error
+ *          Generates the turtle commands of the grammar's order.
+ *  Remarks:
+ *      A grammar file holds one setting or rule per line; blank lines and lines starting with "#" are ignored:
+ *          engine deterministic          (or stochastic, or hogeweg; deterministic by default)
+ *          axiom  $FX
+ *          angle  90
+ *          order  10
+ *          X -> X-YF-
+ *          Y -> +FX+Y
+ *      The settings may also be written "key: value" or "key = value". Deterministic rules take precedence in the order
+ *      of the file: at each position, the first rule whose predecessor matches is applied, as by DeterministicPairs,
+ *      whereas Derive tries the longer predecessors first. Stochastic rules rewrite "F" and may be followed by a
+ *      positive integer weight, e.g., "F -> F[+F]F 2". Hogeweg and Hesper rules are written with their contexts, e.g.,
+ *      "0 < 1 > 1 -> 1[+F1F1]".
+ *  History: v1.15.0 - October 18, 2026 - Original release.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "bufio"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "regexp"
+    "strconv"
+    "strings"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type Grammar struct {
+    ENGINE       string            //"deterministic", "stochastic" or "hogeweg"
+    AXIOM        string            //production axiom
+    ANGLE        float64           //production angle in degrees
+    ORDER        int               //order of the curve
+    RULES        map[string]string //deterministic rules keyed by their predecessor, or Hogeweg and Hesper rules keyed
+                                   //by "L < a > R"
+    PREDECESSORS []string          //deterministic predecessors in order of precedence, as listed in the grammar file;
+                                   //the others follow, the longer ones first
+    CHOICES      []string          //stochastic successors of "F"
+    WEIGHTS      []int             //weights of the stochastic successors
+}
+func LoadGrammar(grammarPath string) (Grammar, error) {
+/*         Purpose : Reads a grammar from a text file.
+ *       Arguments : grammarPath = file path of the grammar.
+ *         Returns : the validated grammar, or an error.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : ParseGrammar
+ *         Remarks : See the file header for the format.
+ *         History : v1.15.0 - October 18, 2026 - Original release.
+ */
+    reader, err := os.Open(grammarPath)
+    if err != nil { return Grammar{}, err }
+    defer reader.Close()
+    return ParseGrammar(reader)
+} //end func LoadGrammar
+func ParseGrammar(reader io.Reader) (Grammar, error) {
+/*         Purpose : Reads a grammar from an io.Reader.
+ *       Arguments : reader = source of the grammar.
+ *         Returns : the validated grammar, or an error giving the offending line number.
+ * Externals -  In : _reGrammarContext
+ * Externals - Out : None.
+ *       Functions : Validate
+ *         Remarks : See the file header for the format.
+ *         History : v1.15.0 - October 18, 2026 - Original release.
+ */
+    var( angleSet bool
+         grammar  = Grammar{ENGINE: "deterministic", RULES: map[string]string{}}
+         scanner  = bufio.NewScanner(reader)
+    )
+    scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+    for lineNum := 1; scanner.Scan(); lineNum++ {
+        line  := strings.TrimSpace(scanner.Text())
+        fail  := func(format string, args ...interface{}) (Grammar, error) {
+            return Grammar{}, fmt.Errorf("line %d: " + format, append([]interface{}{lineNum}, args...)...)
+        }
+        if line == "" || strings.HasPrefix(line, "#") { continue }
+        //Rules
+        if lhs, rhs, found := strings.Cut(line, "->"); found {
+            predecessor := strings.Join(strings.Fields(lhs), "")
+            fields      := strings.Fields(rhs)
+            successor   := ""
+            weight      := 1
+            switch len(fields) {
+                case 0:
+                case 1:
+                    successor = fields[0]
+                case 2:
+                    var err error
+                    successor = fields[0]
+                    if weight, err = strconv.Atoi(fields[1]); err != nil || weight < 1 {
+                        return fail("the weight %q is not a positive integer", fields[1])
+                    }
+                default:
+                    return fail("the successor must not contain spaces")
+            }
+            if len(fields) == 2 && grammar.ENGINE != "stochastic" { return fail("weights require the stochastic engine") }
+            switch {
+                case predecessor == "":
+                    return fail("the rule has no predecessor")
+                case _reGrammarContext.MatchString(predecessor):
+                    if grammar.ENGINE != "hogeweg" { return fail("context rules require the hogeweg engine") }
+                    key := fmt.Sprintf("%c < %c > %c", predecessor[0], predecessor[2], predecessor[4])
+                    if _, ok := grammar.RULES[key]; ok { return fail("the rule for %s is repeated", key) }
+                    grammar.RULES[key] = successor
+                case grammar.ENGINE == "hogeweg":
+                    return fail("hogeweg rules must be of the form L < a > R -> successor, with 0 or 1 for L, a and R")
+                case grammar.ENGINE == "stochastic":
+                    if predecessor != "F" { return fail("the stochastic engine only rewrites F") }
+                    grammar.CHOICES = append(grammar.CHOICES, successor)
+                    grammar.WEIGHTS = append(grammar.WEIGHTS, weight)
+                default:
+                    if _, ok := grammar.RULES[predecessor]; ok { return fail("the rule for %s is repeated", predecessor) }
+                    grammar.RULES[predecessor] = successor
+                    grammar.PREDECESSORS       = append(grammar.PREDECESSORS, predecessor)
+            }
+            continue
+        }
+        //Settings
+        split := strings.IndexAny(line, " \t:=")
+        if split < 0 { return fail("%q is neither a setting nor a rule", line) }
+        key, value := strings.ToLower(line[:split]), strings.TrimSpace(strings.TrimLeft(line[split:], " \t:="))
+        if value == "" { return fail("the %s has no value", key) }
+        switch key {
+            case "engine":
+                if len(grammar.RULES) > 0 || len(grammar.CHOICES) > 0 { return fail("the engine must precede the rules") }
+                switch value {
+                    case "deterministic", "stochastic", "hogeweg":
+                    default: return fail("the engine %q is not one of deterministic, stochastic or hogeweg", value)
+                }
+                grammar.ENGINE = value
+            case "axiom":
+                grammar.AXIOM = value
+            case "angle":
+                angle, err := strconv.ParseFloat(value, 64)
+                if err != nil { return fail("the angle %q is not a number", value) }
+                grammar.ANGLE, angleSet = angle, true
+            case "order":
+                order, err := strconv.Atoi(value)
+                if err != nil { return fail("the order %q is not an integer", value) }
+                grammar.ORDER = order
+            default:
+                return fail("the setting %q is unknown", key)
+        }
+    }
+    if err := scanner.Err(); err != nil { return Grammar{}, err }
+    if !angleSet { return Grammar{}, errors.New("the angle was not specified") }
+    if err := grammar.Validate(); err != nil { return Grammar{}, err }
+    return grammar, nil
+} //end func ParseGrammar
+func(grammar Grammar) Validate() error {
+/*         Purpose : Checks that the grammar can be derived without halting.
+ *       Arguments : None.
+ *         Returns : nil, or an error describing the first problem found.
+ * Externals -  In : _reGrammarKey
+ * Externals - Out : None.
+ *       Functions : hogewegSymbols, rulePairs
+ *         Remarks : The engines halt the program on the problems reported here.
+ *         History : v1.15.0 - October 18, 2026 - Original release.
+ */
+    if grammar.AXIOM == ""  { return errors.New("the axiom was not specified") }
+    if grammar.ANGLE == 0.  { return errors.New("the angle is zero") }
+    if grammar.ORDER < 0    { return errors.New("the order must be non-negative") }
+    switch grammar.ENGINE {
+        case "deterministic":
+            for predecessor := range grammar.RULES {
+                if predecessor == "" { return errors.New("a rule has no predecessor") }
+            }
+            if _, err := rulePairs(grammar.RULES, grammar.PREDECESSORS); err != nil { return err }
+        case "stochastic":
+            if len(grammar.CHOICES) == 0 { return errors.New("the stochastic engine requires at least one rule") }
+            if len(grammar.WEIGHTS) < len(grammar.CHOICES) { return errors.New("fewer weights than rules were specified") }
+            for _, weight := range grammar.WEIGHTS {
+                if weight < 1 { return errors.New("the weights must be positive") }
+            }
+        case "hogeweg":
+            if !hogewegSymbols(grammar.AXIOM) {
+                return errors.New("the axiom must be balanced and made of the symbols F + - $ [ ] 0 1")
+            }
+            for key, successor := range grammar.RULES {
+                if !_reGrammarKey.MatchString(key) { return fmt.Errorf("the rule %q is not of the form L < a > R", key) }
+                if successor == "" || !hogewegSymbols(successor) {
+                    return fmt.Errorf("the successor of %s must be balanced and made of the symbols F + - $ [ ] 0 1", key)
+                }
+            }
+        default:
+            return fmt.Errorf("the engine %q is not one of deterministic, stochastic or hogeweg", grammar.ENGINE)
+    }
+    return nil
+} //end func Validate
+func(grammar Grammar) Step(turtleCmds string, maxSymbols int) (string, error) {
+/*         Purpose : Applies one derivation step of the grammar's engine to the given turtle commands.
+ *       Arguments : turtleCmds = turtle commands of the current order.
+ *                   maxSymbols = most symbols allowed in the result, or 0 for no limit.
+ *         Returns : the turtle commands of the next order, or an error.
+ * Externals -  In : TurtleCmds
+ * Externals - Out : TurtleCmds
+ *       Functions : HogewegHesper, Stochastic, Validate, derivePairs, rulePairs
+ *         Remarks : - The stochastic and hogeweg steps are refused if their longest possible result exceeds the limit.
+ *                   - At each position, the first deterministic rule whose predecessor matches is applied, in the order
+ *                     of PREDECESSORS, i.e., of the grammar file, as by DeterministicPairs. The unlisted predecessors
+ *                     follow, the longer ones first, as by Derive.
+ *         History : v1.15.0 - October 18, 2026 - Original release.
+ */
+    if err := grammar.Validate(); err != nil { return "", err }
+    if turtleCmds == "" { return "", nil } //the engines require an axiom
+
+    if grammar.ENGINE == "deterministic" {
+        if maxSymbols < 0 { return "", errors.New("the symbol limit must be non-negative") }
+        oldnew, err := rulePairs(grammar.RULES, grammar.PREDECESSORS)
+        if err != nil { return "", err }
+        return derivePairs(1, turtleCmds, oldnew, maxSymbols)
+    }
+    if maxSymbols > 0 {
+        //bound the length of the result by the longest successor of the rewritten symbols
+        longest, rewritten := 1, "F"
+        if grammar.ENGINE == "hogeweg" { rewritten = "01" }
+        for _, successor := range grammar.CHOICES {
+            if len(successor) > longest { longest = len(successor) }
+        }
+        for _, successor := range grammar.RULES {
+            if len(successor) > longest { longest = len(successor) }
+        }
+        count := 0
+        for _, symbol := range rewritten {
+            count += strings.Count(turtleCmds, string(symbol))
+        }
+        if bound := len(turtleCmds) + count * (longest - 1); bound > maxSymbols {
+            return "", fmt.Errorf("the next derivation could have %d symbols, above the limit of %d", bound, maxSymbols)
+        }
+    }
+    if grammar.ENGINE == "stochastic" {
+        Stochastic(1, turtleCmds, grammar.CHOICES, grammar.WEIGHTS)
+    } else {
+        HogewegHesper(1, turtleCmds, grammar.RULES)
+    }
+    return TurtleCmds, nil
+} //end func Step
+func(grammar Grammar) Generate(maxSymbols int) error {
+/*         Purpose : Generates the turtle commands of the grammar's order.
+ *       Arguments : maxSymbols = most symbols allowed in any derivation step, or 0 for no limit.
+ *         Returns : nil, or an error leaving TurtleCmds unchanged.
+ * Externals -  In : TurtleCmds
+ * Externals - Out : TurtleCmds
+ *       Functions : Step
+ *         Remarks : None.
+ *         History : v1.15.0 - October 18, 2026 - Original release.
+ */
+    if err := grammar.Validate(); err != nil { return err }
+
+    saved      := TurtleCmds
+    turtleCmds := grammar.AXIOM
+    for n := 1; n <= grammar.ORDER; n++ {
+        var err error
+        if turtleCmds, err = grammar.Step(turtleCmds, maxSymbols); err != nil {
+            TurtleCmds = saved
+            return fmt.Errorf("order %d: %v", n, err)
+        }
+    }
+    TurtleCmds = turtleCmds
+    return nil
+} //end func Generate
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+var( _reGrammarContext = regexp.MustCompile(`^[01]<[01]>[01]$`)
+     _reGrammarKey     = regexp.MustCompile(`^[01] < [01] > [01]$`)
+)
+func hogewegSymbols(symbols string) bool {
+    //reports whether the symbols are balanced and all supported by HogewegHesper, whose context searches need both
+    depth := 0
+    for _, symbol := range symbols {
+        switch symbol {
+            case '[': depth++
+            case ']': depth--
+            case 'F', '+', '-', '$', '0', '1':
+            default:  return false
+        }
+        if depth < 0 { return false }
+    }
+    return depth == 0
+} //end func hogewegSymbols
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of grammar.go
@@ -0,0 +1,221 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      symbol growth of deterministic context-free L-systems predicted from their production matrix.
+ *  Types:
+ *      GrowthAnalysis
+ *          Exact symbol counts per order and asymptotic growth rate of a deterministic context-free L-system.
+ *  Functions:
+ *      AnalyzeGrowth(order int, axiom string, rules map[string]string, maxBytes int64) GrowthAnalysis
+ *          Predicts the symbol counts of the orders 0 to order without deriving them.
+ *  Remarks:
+ *      Row i of the production matrix counts the occurrences of each symbol in the successor of symbol i, symbols
+ *      without a rule being rewritten as themselves. The counts of order n+1 are then the counts of order n times the
+ *      matrix, and the string length grows asymptotically as a power of the matrix's dominant eigenvalue (its
+ *      Perron-Frobenius root).
+ *  History: v1.17.0 - October 18, 2026 - Original release.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "fmt"
+    "math"
+    "math/big"
+    "strings"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type GrowthAnalysis struct {
+    SYMBOLS   string       //alphabet in byte order
+    MATRIX    [][]int      //MATRIX[i][j] = occurrences of SYMBOLS[j] in the successor of SYMBOLS[i]
+    COUNTS    [][]*big.Int //COUNTS[n][j] = occurrences of SYMBOLS[j] at order n
+    LENGTHS   []*big.Int   //LENGTHS[n] = length of the turtle commands at order n
+    GROWTH    float64      //dominant eigenvalue of the production matrix, i.e., the asymptotic growth factor per order
+    MAX_ORDER int          //highest order, up to the requested one, fitting in the memory limit; -1 if the axiom does not
+    WARNING   string       //description of the memory overrun of the requested order, or ""
+}
+func AnalyzeGrowth(order int, axiom string, rules map[string]string, maxBytes int64) GrowthAnalysis {
+/*         Purpose : Predicts the symbol counts of the orders 0 to order of a deterministic context-free L-system without
+ *                   deriving them.
+ *       Arguments : order    = highest order to predict.
+ *                   axiom    = production axiom.
+ *                   rules    = production rules keyed by their single-symbol predecessor, as passed in pairs to the
+ *                              strings.NewReplacer given to Deterministic.
+ *                   maxBytes = memory limit of a derivation in bytes, or 0 for no limit.
+ *         Returns : the analysis.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : dominantEigenvalue, halt
+ *         Remarks : - Symbols are bytes, so that the lengths match those of TurtleCmds.
+ *                   - A derivation holds the turtle commands of two successive orders at once, so that the memory
+ *                     needed for order n is estimated as the sum of the lengths of orders n-1 and n.
+ *         History : v1.17.0 - October 18, 2026 - Original release.
+ */
+    if order < 0    { halt("curve order must be non-negative") }
+    if axiom == ""  { halt("axiom was not specified") }
+    if maxBytes < 0 { halt("the memory limit must be non-negative") }
+    for predecessor := range rules {
+        if len(predecessor) != 1 { halt("the rules must have single-symbol predecessors") }
+    }
+
+    var( analysis GrowthAnalysis
+         present  [256]bool
+         index    [256]int
+    )
+    //Collect the alphabet
+    for _, text := range append([]string{axiom}, ruleTexts(rules)...) {
+        for k := 0; k < len(text); k++ {
+            present[text[k]] = true
+        }
+    }
+    var alphabet strings.Builder
+    for symbol := 0; symbol < 256; symbol++ {
+        if present[symbol] {
+            index[symbol] = alphabet.Len()
+            alphabet.WriteByte(byte(symbol))
+        }
+    }
+    analysis.SYMBOLS = alphabet.String()
+    size := len(analysis.SYMBOLS)
+    //Build the production matrix
+    analysis.MATRIX = make([][]int, size)
+    for i := 0; i < size; i++ {
+        analysis.MATRIX[i] = make([]int, size)
+        successor, ok := rules[analysis.SYMBOLS[i:i+1]]
+        if !ok { successor = analysis.SYMBOLS[i:i+1] }
+        for k := 0; k < len(successor); k++ {
+            analysis.MATRIX[i][index[successor[k]]]++
+        }
+    }
+    //Propagate the exact counts
+    counts := make([]*big.Int, size)
+    for j := range counts {
+        counts[j] = new(big.Int)
+    }
+    for k := 0; k < len(axiom); k++ {
+        counts[index[axiom[k]]].Add(counts[index[axiom[k]]], big.NewInt(1))
+    }
+    term := new(big.Int)
+    for n := 0; n <= order; n++ {
+        length := new(big.Int)
+        for _, count := range counts {
+            length.Add(length, count)
+        }
+        analysis.COUNTS  = append(analysis.COUNTS, counts)
+        analysis.LENGTHS = append(analysis.LENGTHS, length)
+        if n == order { break }
+        next := make([]*big.Int, size)
+        for j := range next {
+            next[j] = new(big.Int)
+        }
+        for i, count := range counts {
+            if count.Sign() == 0 { continue }
+            for j, m := range analysis.MATRIX[i] {
+                if m == 0 { continue }
+                next[j].Add(next[j], term.Mul(count, big.NewInt(int64(m))))
+            }
+        }
+        counts = next
+    }
+    //Estimate the growth rate
+    analysis.GROWTH = dominantEigenvalue(analysis.MATRIX)
+    //Check the memory needed
+    analysis.MAX_ORDER = order
+    if maxBytes > 0 {
+        limit := big.NewInt(maxBytes)
+        for n := 0; n <= order; n++ {
+            needed := new(big.Int).Set(analysis.LENGTHS[n])
+            if n > 0 { needed.Add(needed, analysis.LENGTHS[n-1]) }
+            if needed.Cmp(limit) > 0 {
+                analysis.MAX_ORDER = n - 1
+                analysis.WARNING   = fmt.Sprintf("order %d needs about %s bytes, above the limit of %d bytes", n,
+                                                 needed.String(), maxBytes)
+                if n > 0 { analysis.WARNING += fmt.Sprintf("; the highest order within the limit is %d", n - 1) }
+                break
+            }
+        }
+    }
+    return analysis
+} //end func AnalyzeGrowth
+func(analysis GrowthAnalysis) String() string {
+/*         Purpose : Formats the analysis as a multi-line report.
+ *       Arguments : None.
+ *         Returns : the report.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : None.
+ *         History : v1.17.0 - October 18, 2026 - Original release.
+ */
+    var report strings.Builder
+    fmt.Fprintf(&report, "growth factor %.6f per order\n", analysis.GROWTH)
+    fmt.Fprintf(&report, "order  length")
+    for k := 0; k < len(analysis.SYMBOLS); k++ {
+        fmt.Fprintf(&report, "  %c", analysis.SYMBOLS[k])
+    }
+    report.WriteString("\n")
+    for n, length := range analysis.LENGTHS {
+        fmt.Fprintf(&report, "%5d  %s", n, length.String())
+        for _, count := range analysis.COUNTS[n] {
+            fmt.Fprintf(&report, "  %s", count.String())
+        }
+        report.WriteString("\n")
+    }
+    if analysis.WARNING != "" { fmt.Fprintf(&report, "warning: %s\n", analysis.WARNING) }
+    return report.String()
+} //end func String
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+func ruleTexts(rules map[string]string) (texts []string) {
+    for predecessor, successor := range rules {
+        texts = append(texts, predecessor, successor)
+    }
+    return
+} //end func ruleTexts
+func dominantEigenvalue(matrix [][]int) float64 {
+/*         Purpose : Computes the dominant eigenvalue of a non-negative square matrix.
+ *       Arguments : matrix = non-negative square matrix.
+ *         Returns : the spectral radius.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : Power iteration is applied to the matrix plus the identity, whose dominant eigenvalue is the spectral
+ *                   radius plus one and is strictly dominant even for periodic matrices. Defective matrices, such as
+ *                   those of polynomially growing systems, converge slowly and are stopped after 100000 iterations.
+ *         History : v1.17.0 - October 18, 2026 - Original release.
+ */
+    const( maxIterations = 100000
+           tolerance     = 1e-12
+    )
+    size := len(matrix)
+    if size == 0 { return 0. }
+    vector := make([]float64, size)
+    for i := range vector {
+        vector[i] = 1.
+    }
+    estimate := 0.
+    for iteration := 0; iteration < maxIterations; iteration++ {
+        next := make([]float64, size)
+        for i := range matrix { //next = vector * (matrix + I), i.e., counts propagated by one order
+            next[i] += vector[i]
+            for j, m := range matrix[i] {
+                next[j] += vector[i] * float64(m)
+            }
+        }
+        norm := 0.
+        for _, v := range next {
+            norm = math.Max(norm, v)
+        }
+        for i := range next {
+            next[i] /= norm
+        }
+        vector = next
+        if math.Abs(norm - estimate) <= tolerance * norm {
+            estimate = norm
+            break
+        }
+        estimate = norm
+    }
+    return estimate - 1.
+} //end func dominantEigenvalue
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of growth.go
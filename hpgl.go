@@ -0,0 +1,440 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      HP-GL/2 output to io.Writer destinations with control over the media, units, orientation, margins and velocity.
+ *  Types:
+ *      HpglMedia
+ *          Media and device settings for HP-GL/2 output.
+ *      PenAssignment
+ *          Criterion assigning strokes to the pens of a multi-pen plotter: PenBySubplot, PenByDepth, PenByColor or
+ *          PenByStrahler.
+ *  Variables:
+ *      MediaA3, MediaA4, MediaLetter HpglMedia
+ *          Common sheet sizes in portrait orientation with 5 mm margins.
+ *  Functions:
+ *      RollMedia(width, length float64) HpglMedia
+ *          Returns the settings for a roll of the given width and plot length in millimeters.
+ *      HpglPlotWriter(writer io.Writer, angle float64, plotTitle string, penWidth float64, media HpglMedia)
+ *          Converts the latest generated turtle commands to an HP-GL/2 command set written to an io.Writer. The resulting
+ *          plot will be isometrically scaled and centered on the media.
+ *      HpglGridPlotWriter(writer io.Writer, turtleCmds []string, turtleAngles []float64, layout GridLayout,
+ *                         plotTitle string, titles, labels []string, penWidth float64, media HpglMedia)
+ *          Converts a set of turtle commands on a grid to an HP-GL/2 command set written to an io.Writer. Each subplot is
+ *          scaled and centered in its cell according to the layout's scaling mode.
+ *  History: v1.6.0 - October 18, 2026 - Original release.
+ *           v1.7.0 - October 18, 2026 - Added multi-pen plots.
+ *           v1.8.0 - October 18, 2026 - Added pen-travel optimisation.
+ *           v1.14.0 - October 18, 2026 - Added error-returning validation for RenderHpgl.
+ *           v1.20.0 - October 18, 2026 - Added pen assignment by Horton-Strahler order.
+ *           v1.22.0 - October 18, 2026 - Added vertex welding.
+ *           v1.24.0 - October 18, 2026 - Built the stroke commands without recopying them.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "errors"
+    "fmt"
+    "io"
+    "math"
+    "sort"
+    "strings"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type HpglMedia struct {
+    WIDTH         float64       //width of the media in millimeters, i.e., the shorter side of a sheet or the width of a roll
+    LENGTH        float64       //length of the media in millimeters
+    LANDSCAPE     bool          //true to rotate the plot by 90 degrees
+    MARGIN        float64       //blank border in millimeters
+    PLOTTER_UNITS bool          //true to output integer plotter units (0.025 mm) instead of scaled user units
+    VELOCITY      float64       //pen velocity in centimeters per second; 0 for the plotter's default
+    PENS          []float64     //line widths in millimeters of pens 1, 2, ..., overriding penWidth; nil for a single pen
+    PEN_BY        PenAssignment //criterion assigning the strokes to the pens
+    PEN_SORT      bool          //true to complete all the strokes of a pen before switching to the next one
+}
+type PenAssignment int
+const(
+    PenBySubplot PenAssignment = iota //one pen per subplot
+    PenByDepth                        //one pen per branch depth
+    PenByColor                        //one pen per color index, as set with the ";" and "," symbols
+    PenByStrahler                     //one pen per Horton-Strahler order, Pen 1 drawing the twigs, the last pen any higher
+)
+var( MediaA3     = HpglMedia{WIDTH: 297.,   LENGTH: 420.,   MARGIN: 5.} //ISO A3
+     MediaA4     = HpglMedia{WIDTH: 210.,   LENGTH: 297.,   MARGIN: 5.} //ISO A4
+     MediaLetter = HpglMedia{WIDTH: 215.9,  LENGTH: 279.4,  MARGIN: 5.} //US Letter
+)
+func RollMedia(width, length float64) HpglMedia {
+/*         Purpose : Returns the settings for a roll of the given width and plot length in millimeters.
+ *       Arguments : width  = roll width in millimeters, e.g., 610 or 914 for 24 or 36 inch rolls.
+ *                   length = length of the plot along the roll in millimeters.
+ *         Returns : media settings, in landscape orientation with 5 mm margins.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : None.
+ *         History : v1.6.0 - October 18, 2026 - Original release.
+ */
+    return HpglMedia{WIDTH: width, LENGTH: length, LANDSCAPE: true, MARGIN: 5.}
+} //end func RollMedia
+func HpglPlotWriter(writer io.Writer, angle float64, plotTitle string, penWidth float64, media HpglMedia) {
+/*         Purpose : Converts the latest generated turtle commands to an HP-GL/2 command set written to an io.Writer.
+ *                   The resulting plot will be isometrically scaled and centered on the media.
+ *       Arguments : writer    = destination of the HP-GL/2 commands.
+ *                   angle     = production angle in degrees.
+ *                   plotTitle = title to be centered at the top of the plot.
+ *                   penWidth  = line-width in millimeters, unless the pen widths are set.
+ *                   media     = media and device settings.
+ *         Returns : None.
+ * Externals -  In : OptimizePenTravel, TurtleCmds, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : PenTravel
+ *       Functions : assignStrahler, halt, hpglPlotCmds, logo2Geometry, resetPenTravel, streamWrite, validMedia,
+ *                   weldGeometry
+ *         Remarks : Supported L-system constants are F f + - | $ ( ) [ ] { } ; ,
+ *                   All other symbols will be ignored.
+ *                   The default turtle heading is 0 degrees.
+ *                   If OptimizePenTravel is set, the strokes are merged and reordered to minimise the pen-up travel.
+ *         History : v1.6.0 - October 18, 2026 - Original release.
+ *                   v1.7.0 - October 18, 2026 - Added multi-pen plots.
+ *                   v1.8.0 - October 18, 2026 - Added pen-travel optimisation.
+ *                   v1.14.0 - October 18, 2026 - Moved the composition to hpglPlotCmds.
+ */
+    if TurtleCmds == "" { halt("the turtle commands were not generated") }
+    if angle      == 0. { halt("the production angle is zero") }
+    if writer     == nil { halt("the writer for the plot was not specified") }
+    validMedia(media)
+
+    //Convert the turtle commands to line segments and polygons using unit turtle strides
+    geometry := logo2Geometry(TurtleCmds, angle, nil)
+    if media.PEN_BY == PenByStrahler { assignStrahler(&geometry) }
+    weldGeometry(&geometry)
+    //Compose the HP-GL/2 commands
+    report   := resetPenTravel()
+    plotCmds := hpglPlotCmds(&geometry, plotTitle, penWidth, media, report)
+    //Output the commands to the specified destination
+    streamWrite(writer, plotCmds)
+    return
+} //end func HpglPlotWriter
+func HpglGridPlotWriter(writer io.Writer, turtleCmds []string, turtleAngles []float64, layout GridLayout,
+                        plotTitle string, titles, labels []string, penWidth float64, media HpglMedia) {
+/*         Purpose : Converts a set of turtle commands on a grid to an HP-GL/2 command set written to an io.Writer. Each
+ *                   subplot is scaled and centered in its cell according to the layout's scaling mode.
+ *       Arguments : writer       = destination of the HP-GL/2 commands.
+ *                   turtleCmds   = slice of turtle commands.
+ *                   turtleAngles = slice of production angles in degrees.
+ *                   layout       = grid layout. Use one row to generate the subplots left to right.
+ *                   plotTitle    = title to be centered at the top of the plot.
+ *                   titles       = slice of titles to be centered above each subplot, or nil.
+ *                   labels       = slice of labels to be centered below each subplot, or nil.
+ *                   penWidth     = line-width in millimeters, unless the pen widths are set.
+ *                   media        = media and device settings.
+ *         Returns : None.
+ * Externals -  In : OptimizePenTravel, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : PenTravel
+ *       Functions : assignStrahler, geometry2Hpgl, halt, hpglPrologue, layoutGrid, logo2Geometry, makeFit2Box,
+ *                   makePenSelector, resetPenTravel, streamWrite, strokes2Hpgl, validGrid, validMedia,
+ *                   weldGeometry
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { } ; ,
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - The subplots fill the grid row by row, from the top left cell.
+ *                   - The titles and labels are drawn with Pen 1.
+ *                   - If OptimizePenTravel is set, the strokes of each subplot are merged and reordered to minimise
+ *                     the pen-up travel.
+ *         History : v1.6.0 - October 18, 2026 - Original release.
+ *                   v1.7.0 - October 18, 2026 - Added multi-pen plots.
+ *                   v1.8.0 - October 18, 2026 - Added pen-travel optimisation.
+ *                   v1.20.0 - October 18, 2026 - Added pen assignment by Horton-Strahler order.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ */
+    validGrid(turtleCmds, turtleAngles, layout, titles, labels)
+    if writer == nil { halt("the writer for the plot was not specified") }
+    validMedia(media)
+
+    const ext = 3 //End of Text code
+    var(  geometries = make([]_geometry, len(turtleCmds))
+          strokes    []_hpglStroke
+          isotropic  = layout.SCALING != Anisometric
+          grid2Page  = func(p _point) _point { return p }
+          decimals   = 6
+    )
+    //Interpret the turtle commands and lay out the subplots
+    for k, v := range turtleCmds {
+        geometries[k] = logo2Geometry(v, turtleAngles[k], nil)
+        if media.PEN_BY == PenByStrahler { assignStrahler(&geometries[k]) }
+        weldGeometry(&geometries[k])
+    }
+    cells, columns, rows := layoutGrid(geometries, layout, titles, labels)
+    //Compose the HP-GL/2 commands
+    plotCmds, box := hpglPrologue(media, plotTitle, penWidth)
+    report        := resetPenTravel()
+    if media.PLOTTER_UNITS {
+        grid2Page, decimals = makeFit2Box(0., float64(columns), 0., float64(rows), box, isotropic), 0
+    } else {
+        //set the scaling of user units within the drawing box
+        plotCmds += fmt.Sprintf("SC%f,%f,%f,%f,%d;\n", 0., float64(columns), 0., float64(rows),
+                                map[bool]int{true: 1, false: 0} [isotropic])
+    }
+    for k := range geometries {
+        if titles != nil && titles[k] != "" {
+            anchor    := grid2Page(cells[k].TITLE)
+            plotCmds  += fmt.Sprintf("PU%.*f,%.*f;LO5;LB%s%c;\n", decimals, anchor.X, decimals, anchor.Y, titles[k], ext)
+        }
+        if labels != nil && labels[k] != "" {
+            anchor    := grid2Page(cells[k].LABEL)
+            plotCmds  += fmt.Sprintf("PU%.*f,%.*f;LO5;LB%s%c;\n", decimals, anchor.X, decimals, anchor.Y, labels[k], ext)
+        }
+        fit    := cells[k].FIT
+        strokes = append(strokes, geometry2Hpgl(&geometries[k], func(p _point) _point { return grid2Page(fit(p)) },
+                                                decimals, makePenSelector(media, k), report)...)
+    }
+    plotCmds += strokes2Hpgl(strokes, media.PEN_SORT)
+    //end (page advance)
+    plotCmds += "PG;\n"
+    //Output the commands to the specified destination
+    streamWrite(writer, plotCmds)
+    return
+} //end func HpglGridPlotWriter
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+type _hpglStroke struct {
+    PEN  int    //pen number
+    CMDS string //HP-GL/2 commands drawing the stroke
+}
+type _box struct {
+    X      float64 //left edge
+    Y      float64 //bottom edge
+    WIDTH  float64
+    HEIGHT float64
+}
+const _pluPerMm = 40. //HP-GL/2 plotter units per millimeter
+func hpglPlotCmds(geometry *_geometry, plotTitle string, penWidth float64, media HpglMedia,
+                  report *TravelReport) (plotCmds string) {
+/*         Purpose : Composes the HP-GL/2 command set of a single plot, isometrically scaled and centered on the media.
+ *       Arguments : geometry  = turtle geometry, with its Horton-Strahler orders assigned if the pens are assigned by
+ *                               order.
+ *                   plotTitle = title to be centered at the top of the plot.
+ *                   penWidth  = line-width in millimeters, unless the pen widths are set.
+ *                   media     = validated media and device settings.
+ *                   report    = pen-travel statistics to accumulate, or nil to plot the strokes in turtle order.
+ *         Returns : HP-GL/2 commands.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : geometry2Hpgl, hpglPrologue, makeFit2Box, makePenSelector, padSpan, strokes2Hpgl
+ *         Remarks : Shared by HpglPlotWriter and RenderHpgl.
+ *         History : v1.14.0 - October 18, 2026 - Original release.
+ *                   v1.20.0 - October 18, 2026 - Added pen assignment by Horton-Strahler order.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ *                   v1.24.0 - October 18, 2026 - Moved the interpretation to the callers.
+ */
+    //Compose the HP-GL/2 commands
+    plotCmds, box := hpglPrologue(media, plotTitle, penWidth)
+    penOf         := makePenSelector(media, 0)
+    if media.PLOTTER_UNITS {
+        plotCmds += strokes2Hpgl(geometry2Hpgl(geometry, makeFit2Box(geometry.XMIN, geometry.XMAX, geometry.YMIN,
+                                                                      geometry.YMAX, box, true), 0, penOf, report),
+                                 media.PEN_SORT)
+    } else {
+        //set the isotropic scaling of user units within the drawing box
+        xMin, xMax := padSpan(geometry.XMIN, geometry.XMAX)
+        yMin, yMax := padSpan(geometry.YMIN, geometry.YMAX)
+        plotCmds   += fmt.Sprintf("SC%f,%f,%f,%f,1;\n", xMin, xMax, yMin, yMax) +
+                    strokes2Hpgl(geometry2Hpgl(geometry, func(p _point) _point { return p }, 6, penOf, report),
+                                 media.PEN_SORT)
+    }
+    //end (page advance)
+    plotCmds += "PG;\n"
+    return
+} //end func hpglPlotCmds
+func validMedia(media HpglMedia) {
+    if err := checkMedia(media); err != nil { halt(err.Error()) }
+    return
+} //end func validMedia
+func checkMedia(media HpglMedia) error {
+    if !(media.WIDTH > 0.) || !(media.LENGTH > 0.) { return errors.New("the media size must be positive") }
+    if media.MARGIN < 0. || 2. * media.MARGIN >= math.Min(media.WIDTH, media.LENGTH) {
+        return errors.New("the media margin is not valid")
+    }
+    if media.VELOCITY < 0. { return errors.New("the pen velocity must be non-negative") }
+    return checkPenSettings(media)
+} //end func checkMedia
+func checkPenSettings(media HpglMedia) error {
+    if err := checkPens(media.PENS); err != nil { return err }
+    if media.PEN_BY < PenBySubplot || media.PEN_BY > PenByStrahler { return errors.New("the pen assignment is not valid") }
+    return nil
+} //end func checkPenSettings
+func hpglPrologue(media HpglMedia, plotTitle string, penWidth float64) (plotCmds string, box _box) {
+/*         Purpose : Composes the HP-GL/2 commands setting up the media, the drawing area, the pen and the plot title.
+ *       Arguments : media     = media and device settings.
+ *                   plotTitle = title to be centered at the top of the plot.
+ *                   penWidth  = line-width in millimeters, unless the pen widths are set.
+ *         Returns : the commands and the drawing box in plotter units.
+ * Externals -  In : _pluPerMm
+ * Externals - Out : None.
+ *       Functions : hpglPenWidths
+ *         Remarks : - The media size is declared with its width along the X axis; landscape plots are rotated with RO90.
+ *                   - The scaling points P1 and P2 are set to the corners of the drawing box.
+ *         History : v1.6.0 - October 18, 2026 - Original release.
+ */
+    const( esc        = 27  //Escape code
+           ext        = 3   //End of Text code
+           titleBand  = 8.  //mm - room for the title
+    )
+    pageX, pageY := media.WIDTH, media.LENGTH
+    if media.LANDSCAPE { pageX, pageY = pageY, pageX }
+    margin := media.MARGIN
+    top    := map[bool]float64{true: titleBand, false: 0.} [plotTitle != ""]
+    box     = _box{margin * _pluPerMm, margin * _pluPerMm,
+                   (pageX - 2. * margin) * _pluPerMm, (pageY - 2. * margin - top) * _pluPerMm}
+    plotCmds = //HP RTL: enter HP-GL/2 mode, begin a plot and initialize HP-GL/2
+               fmt.Sprintf("%c%%-1BBPIN;\n", esc) +
+               //declare the media size in plotter units
+               fmt.Sprintf("PS%.0f,%.0f;\n", media.WIDTH * _pluPerMm, media.LENGTH * _pluPerMm) +
+               //set the orientation
+               map[bool]string{true: "RO90;\n", false: "RO0;\n"} [media.LANDSCAPE] +
+               //set the scaling points to the drawing box
+               fmt.Sprintf("IP%.0f,%.0f,%.0f,%.0f;\n", box.X, box.Y, box.X + box.WIDTH, box.Y + box.HEIGHT) +
+               //select Pen 1 (black) and set the pen widths in millimeters
+               hpglPenWidths(penWidth, media.PENS)
+    if media.VELOCITY > 0. { //set the pen velocity
+        plotCmds += fmt.Sprintf("VS%f;\n", media.VELOCITY)
+    }
+    if plotTitle != "" { //draw the plot title centered at the top, in plotter units
+        plotCmds += fmt.Sprintf("PU%.0f,%.0f;LO4;LB%s%c;\n", 0.5 * pageX * _pluPerMm,
+                                (pageY - margin - 0.5 * titleBand) * _pluPerMm, plotTitle, ext)
+    }
+    return
+} //end func hpglPrologue
+func makeFit2Box(xMin, xMax, yMin, yMax float64, box _box, isotropic bool) func(p _point) _point {
+    if isotropic {
+        fit := makeFit2Page(xMin, xMax, yMin, yMax, box.WIDTH, box.HEIGHT, 0., false)
+        return func(p _point) _point {
+                x, y := fit(p)
+                return _point{box.X + x, box.Y + y}
+               }
+    }
+    xScale := box.WIDTH  / math.Max(xMax - xMin, 1e-9)
+    yScale := box.HEIGHT / math.Max(yMax - yMin, 1e-9)
+    return func(p _point) _point { return _point{box.X + xScale * (p.X - xMin), box.Y + yScale * (p.Y - yMin)} }
+} //end func makeFit2Box
+func padSpan(lo, hi float64) (float64, float64) {
+    //plotters reject a degenerate SC scaling; half a stride either way survives the 6 decimals of the user units
+    if hi - lo < 1e-6 { return 0.5 * (lo + hi) - 0.5, 0.5 * (lo + hi) + 0.5 }
+    return lo, hi
+} //end func padSpan
+func geometry2Hpgl(geometry *_geometry, fit func(p _point) _point, decimals int,
+                   penOf func(depth, color, order int) int, report *TravelReport) (strokes []_hpglStroke) {
+/*         Purpose : Converts turtle geometry to HP-GL/2 strokes.
+ *       Arguments : geometry = turtle geometry.
+ *                   fit      = transform from turtle coordinates to HP-GL/2 coordinates.
+ *                   decimals = number of decimals of the coordinates.
+ *                   penOf    = function selecting the pen of a stroke from its branch depth, color index and
+ *                              Horton-Strahler order, or nil for Pen 1.
+ *                   report   = pen-travel statistics to accumulate, or nil to keep the turtle order.
+ *         Returns : slice of strokes, polygons first.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : chainSegments, optimizeTravel, splitSegments
+ *         Remarks : - Consecutive segments drawn with the same pen are chained into polylines.
+ *                   - With pen-travel optimisation, the segments of each pen are merged and reordered as a whole,
+ *                     the pens following one another in increasing order.
+ *         History : v1.6.0 - October 18, 2026 - Original release.
+ *                   v1.7.0 - October 18, 2026 - Added pen selection.
+ *                   v1.8.0 - October 18, 2026 - Added pen-travel optimisation.
+ *                   v1.20.0 - October 18, 2026 - Added the Horton-Strahler order to the pen selection.
+ *                   v1.24.0 - October 18, 2026 - Built the commands without recopying them.
+ */
+    if penOf == nil { penOf = func(depth, color, order int) int { return 1 } }
+    coords := func(p _point) string {
+        q := fit(p)
+        return fmt.Sprintf("%.*f,%.*f", decimals, q.X, decimals, q.Y)
+    }
+    for _, polygon := range geometry.POLYGONS {
+        var plotCmds strings.Builder
+        plotCmds.WriteString("PU" + coords(polygon.VERTICES[0]) + ";\nPM0;\nPD")
+        for k, vertex := range polygon.VERTICES[1:] {
+            plotCmds.WriteString(map[bool]string{true: "", false: ","} [k == 0] + coords(vertex))
+        }
+        plotCmds.WriteString(";\nPM2;EP;FP;\n")
+        strokes = append(strokes, _hpglStroke{penOf(polygon.DEPTH, polygon.COLOR, polygon.ORDER), plotCmds.String()})
+    }
+    //Split the segments into runs drawn with the same pen, grouping them by pen if the travel is to be optimised
+    runs := splitSegments(geometry.SEGMENTS, penOf, report != nil)
+    //Convert the polylines of each run
+    for _, run := range runs {
+        polylines := chainSegments(run.SEGMENTS)
+        if report != nil { polylines = optimizeTravel(run.SEGMENTS, report) }
+        for _, polyline := range polylines { //long polylines are built without recopying their commands
+            var plotCmds strings.Builder
+            plotCmds.WriteString("PU" + coords(polyline[0]) + ";\nPD")
+            for j, vertex := range polyline[1:] {
+                plotCmds.WriteString(map[bool]string{true: "", false: ","} [j == 0] + coords(vertex))
+            }
+            plotCmds.WriteString(";\n")
+            strokes = append(strokes, _hpglStroke{run.KEY, plotCmds.String()})
+        }
+    }
+    return
+} //end func geometry2Hpgl
+func strokes2Hpgl(strokes []_hpglStroke, penSort bool) (plotCmds string) {
+/*         Purpose : Composes HP-GL/2 strokes, selecting pens as required.
+ *       Arguments : strokes = slice of strokes.
+ *                   penSort = true to complete all the strokes of a pen before switching to the next one.
+ *         Returns : HP-GL/2 commands.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : Pen 1 is assumed to be selected beforehand.
+ *         History : v1.7.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Built the commands without recopying them.
+ */
+    if penSort {
+        sort.SliceStable(strokes, func(i, j int) bool { return strokes[i].PEN < strokes[j].PEN })
+    }
+    var( commands strings.Builder
+         pen      = 1
+    )
+    for _, stroke := range strokes {
+        if stroke.PEN != pen {
+            pen = stroke.PEN
+            commands.WriteString(fmt.Sprintf("SP%d;\n", pen))
+        }
+        commands.WriteString(stroke.CMDS)
+    }
+    return commands.String()
+} //end func strokes2Hpgl
+func validPenSettings(pens []HpglMedia) (settings HpglMedia) {
+    //the optional settings of HpglMultiPlot and HpglGridPlot, which have no use for the media size
+    if len(pens) == 0 { return }
+    if err := checkPenSettings(pens[0]); err != nil { halt(err.Error()) }
+    return HpglMedia{PENS: pens[0].PENS, PEN_BY: pens[0].PEN_BY, PEN_SORT: pens[0].PEN_SORT}
+} //end func validPenSettings
+func checkPens(pens []float64) error {
+    for _, width := range pens {
+        if !(width > 0.) { return errors.New("the pen widths must be positive") }
+    }
+    return nil
+} //end func checkPens
+func hpglPenWidths(penWidth float64, pens []float64) (plotCmds string) {
+    //the widths of the pens, when set, include that of Pen 1, so that penWidth would be overridden
+    if len(pens) == 0 { return fmt.Sprintf("SP1;WU0;PW%f;\n", penWidth) }
+    plotCmds = "SP1;WU0;\n"
+    for k, width := range pens {
+        plotCmds += fmt.Sprintf("PW%f,%d;\n", width, k + 1)
+    }
+    return
+} //end func hpglPenWidths
+func makePenSelector(media HpglMedia, subplot int) func(depth, color, order int) int {
+    numPens := len(media.PENS)
+    if numPens < 2 { return nil }
+    return func(depth, color, order int) int {
+            key := subplot
+            switch media.PEN_BY {
+                case PenByDepth:    key = depth
+                case PenByColor:    key = color
+                case PenByStrahler: key = order - 1
+                                    if order > numPens { key = numPens - 1 } //the higher orders share the last pen
+            }
+            return 1 + ((key % numPens) + numPens) % numPens
+           }
+} //end func makePenSelector
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of hpgl.go
@@ -0,0 +1,257 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      self-contained HTML5 canvas viewer with pan, zoom and, optionally, client-side derivation at other orders.
+ *  Types:
+ *      HtmlGrammar
+ *          Deterministic context-free grammar embedded in an HTML viewer for client-side derivation.
+ *  Functions:
+ *      HtmlViewer(angle float64, plotTitle string, width, height int, lineColor, bgColor string, grammar *HtmlGrammar,
+ *                 htmlPath string)
+ *          Renders the latest generated turtle commands as a self-contained HTML page with an interactive canvas.
+ *  Remarks:
+ *      The page has no external dependencies. Drag to pan, use the mouse wheel to zoom about the pointer and
+ *      double-click to fit the drawing back to the canvas. The embedded turtle interpreter follows the same symbol
+ *      semantics as the package: F f + - | $ ( ) [ ] { }.
+ *  History: v1.13.0 - October 18, 2026 - Original release.
+ *           v1.22.0 - October 18, 2026 - Added vertex welding.
+ *           v1.24.0 - October 18, 2026 - Refused malformed turtle commands in the browser.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "encoding/json"
+    "html"
+    "math"
+    "strconv"
+    "strings"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type HtmlGrammar struct {
+    AXIOM     string            //production axiom
+    RULES     map[string]string //production rules keyed by their single-symbol predecessor
+    ORDER     int               //order of the latest generated turtle commands, i.e., the initial slider position
+    MAX_ORDER int               //highest order offered by the slider
+}
+func HtmlViewer(angle float64, plotTitle string, width, height int, lineColor, bgColor string, grammar *HtmlGrammar,
+                htmlPath string) {
+/*         Purpose : Renders the latest generated turtle commands as a self-contained HTML page with an interactive
+ *                   canvas.
+ *       Arguments : angle     = production angle in degrees.
+ *                   plotTitle = title of the page.
+ *                   width     = canvas width in pixels.
+ *                   height    = canvas height in pixels.
+ *                   lineColor = color of the line segments and polygons, as recognized by CSS.
+ *                   bgColor   = background color, as recognized by CSS, or "" for white.
+ *                   grammar   = grammar to embed for re-deriving the curve at other orders with a slider, or nil.
+ *                   htmlPath  = file path for the page.
+ *         Returns : None.
+ * Externals -  In : TurtleCmds, _degs2rads, _htmlViewer, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : chainSegments, fileWrite, halt, logo2Geometry, weldGeometry
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - The geometry of the latest generated turtle commands is embedded and shown initially. With a
+ *                     grammar, moving the slider re-derives the turtle commands in the browser, which refuses to
+ *                     interpret more than 10 million symbols, a heading declaration that is not well-formed or a
+ *                     branch ending before it starts.
+ *         History : v1.13.0 - October 18, 2026 - Original release.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ *                   v1.24.0 - October 18, 2026 - Refused malformed turtle commands in the browser.
+ */
+    if TurtleCmds == ""        { halt("the turtle commands were not generated") }
+    if angle      == 0.        { halt("the production angle is zero") }
+    if width < 1 || height < 1 { halt("the canvas size must be positive") }
+    if lineColor  == ""        { halt("the line color was not specified") }
+    if bgColor    == ""        { bgColor = "white" }
+    if grammar != nil {
+        if grammar.AXIOM == ""   { halt("axiom was not specified") }
+        if len(grammar.RULES) == 0 { halt("rules were not specified") }
+        for predecessor := range grammar.RULES {
+            if len(predecessor) != 1 { halt("the rules were not specified correctly") }
+        }
+        if grammar.ORDER < 0 || grammar.MAX_ORDER < grammar.ORDER { halt("the grammar orders are not valid") }
+    }
+    if htmlPath   == ""        { halt("the path for the page was not specified") }
+
+    type viewerData struct {
+        Angle    float64      `json:"angle"`
+        Paths    [][]float64  `json:"paths"`
+        Polygons [][]float64  `json:"polygons"`
+        Grammar  *HtmlGrammar `json:"grammar"`
+    }
+    round := func(v float64) float64 { return math.Floor(v * 1e4 + 0.5) / 1e4 }
+    flat  := func(points []_point) (coords []float64) {
+        for _, p := range points {
+            coords = append(coords, round(p.X), round(p.Y))
+        }
+        return
+    }
+    //Interpret the turtle commands and collect the geometry
+    geometry := logo2Geometry(TurtleCmds, angle, nil)
+    weldGeometry(&geometry)
+    data     := viewerData{Angle: angle, Paths: [][]float64{}, Polygons: [][]float64{}, Grammar: grammar}
+    for _, polyline := range chainSegments(geometry.SEGMENTS) {
+        data.Paths = append(data.Paths, flat(polyline))
+    }
+    for _, polygon := range geometry.POLYGONS {
+        data.Polygons = append(data.Polygons, flat(polygon.VERTICES))
+    }
+    encoded, err := json.Marshal(data)
+    if err != nil { halt("json.Marshal - " + err.Error()) }
+    //Compose the page
+    page := strings.NewReplacer("{{TITLE}}",  html.EscapeString(plotTitle),
+                                "{{WIDTH}}",  strconv.Itoa(width),
+                                "{{HEIGHT}}", strconv.Itoa(height),
+                                "{{LINE}}",   strconv.Quote(lineColor),
+                                "{{BG}}",     strconv.Quote(bgColor),
+                                "{{DATA}}",   strings.Replace(string(encoded), "</", `<\/`, -1)).Replace(_htmlViewer)
+    //Output the page to the specified destination
+    fileWrite(htmlPath, page)
+    return
+} //end func HtmlViewer
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+var _htmlViewer = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{TITLE}}</title>
+<style>
+body   { font-family: sans-serif; margin: 1em; }
+canvas { border: 1px solid #ccc; cursor: grab; touch-action: none; }
+#bar   { margin: 0.5em 0; }
+</style>
+</head>
+<body>
+<h3>{{TITLE}}</h3>
+<div id="bar"></div>
+<canvas id="view" width="{{WIDTH}}" height="{{HEIGHT}}"></canvas>
+<script>
+"use strict";
+const data = {{DATA}};
+const lineColor = {{LINE}}, bgColor = {{BG}}, maxSymbols = 1e7;
+const canvas = document.getElementById("view"), ctx = canvas.getContext("2d");
+let paths = data.paths, polygons = data.polygons, view = {scale: 1, x: 0, y: 0};
+
+//Derivation and turtle interpretation, mirroring the Go package
+function derive(order) {
+    const g = data.grammar;
+    let cmds = g.AXIOM;
+    for (let n = 1; n <= order; n++) {
+        let next = "";
+        for (const c of cmds) { next += (c in g.RULES) ? g.RULES[c] : c; }
+        if (next.length > maxSymbols) { return null; }
+        cmds = next;
+    }
+    return cmds;
+}
+function interpret(cmds, angle) { //null if a heading is not well-formed or a branch ends before it starts, as in Go
+    const out = {paths: [], polygons: []}, stack = [], reHeading = /\(([+\-0-9.]+?)\)/y;
+    let t = {h: 0, x: 0, y: 0}, path = null, polygon = null;
+    cmds = cmds.split("+-").join("").split("-+").join("");
+    for (let pos = 0; pos < cmds.length; pos++) {
+        const c = cmds[pos];
+        switch (c) {
+            case "F": case "f": {
+                const x0 = t.x, y0 = t.y;
+                switch (t.h % 360) {
+                    case 0:            t.x += 1; break;
+                    case 90: case -270:  t.y += 1; break;
+                    case 180: case -180: t.x -= 1; break;
+                    case 270: case -90:  t.y -= 1; break;
+                    default: t.x += Math.cos(t.h * Math.PI / 180); t.y += Math.sin(t.h * Math.PI / 180);
+                }
+                if (polygon) { polygon.push(t.x, t.y); }
+                else if (c === "F") {
+                    if (!path || path[path.length-2] !== x0 || path[path.length-1] !== y0) {
+                        path = [x0, y0]; out.paths.push(path);
+                    }
+                    path.push(t.x, t.y);
+                }
+                break;
+            }
+            case "+": t.h += angle; break;
+            case "-": t.h -= angle; break;
+            case "|": t.h += 180; break;
+            case "$": t.h = 90; break;
+            case "(": {
+                reHeading.lastIndex = pos;
+                const match = reHeading.exec(cmds), heading = match ? Number(match[1]) : NaN;
+                if (!Number.isFinite(heading)) { return null; }
+                t.h = heading; pos += match[0].length - 1; break;
+            }
+            case "[": stack.push({h: t.h, x: t.x, y: t.y}); break;
+            case "]": if (stack.length === 0) { return null; } t = stack.pop(); break;
+            case "{": polygon = [t.x, t.y]; break;
+            case "}": if (polygon && polygon.length > 4) { out.polygons.push(polygon); } polygon = null; break;
+        }
+    }
+    return out;
+}
+
+//Viewing
+function fit() {
+    let xMin = 0, xMax = 0, yMin = 0, yMax = 0;
+    for (const p of paths.concat(polygons)) {
+        for (let k = 0; k < p.length; k += 2) {
+            xMin = Math.min(xMin, p[k]); xMax = Math.max(xMax, p[k]);
+            yMin = Math.min(yMin, p[k+1]); yMax = Math.max(yMax, p[k+1]);
+        }
+    }
+    const margin = 10;
+    view.scale = Math.min((canvas.width - 2*margin) / Math.max(xMax - xMin, 1e-9),
+                          (canvas.height - 2*margin) / Math.max(yMax - yMin, 1e-9));
+    view.x = 0.5 * (canvas.width  - view.scale * (xMax - xMin)) - view.scale * xMin;
+    view.y = 0.5 * (canvas.height - view.scale * (yMax - yMin)) + view.scale * yMax;
+    draw();
+}
+function trace(p) {
+    ctx.beginPath();
+    ctx.moveTo(view.x + view.scale * p[0], view.y - view.scale * p[1]);
+    for (let k = 2; k < p.length; k += 2) { ctx.lineTo(view.x + view.scale * p[k], view.y - view.scale * p[k+1]); }
+}
+function draw() {
+    ctx.fillStyle = bgColor; ctx.fillRect(0, 0, canvas.width, canvas.height);
+    ctx.strokeStyle = lineColor; ctx.fillStyle = lineColor; ctx.lineWidth = 1; ctx.lineJoin = "round";
+    for (const p of polygons) { trace(p); ctx.closePath(); ctx.fill("evenodd"); ctx.stroke(); }
+    for (const p of paths) { trace(p); ctx.stroke(); }
+}
+let drag = null;
+canvas.addEventListener("pointerdown", e => { drag = {x: e.offsetX, y: e.offsetY}; canvas.setPointerCapture(e.pointerId); });
+canvas.addEventListener("pointermove", e => {
+    if (!drag) { return; }
+    view.x += e.offsetX - drag.x; view.y += e.offsetY - drag.y; drag = {x: e.offsetX, y: e.offsetY}; draw();
+});
+canvas.addEventListener("pointerup", () => { drag = null; });
+canvas.addEventListener("wheel", e => {
+    e.preventDefault();
+    const factor = Math.exp(-0.002 * e.deltaY);
+    view.x = e.offsetX - factor * (e.offsetX - view.x); view.y = e.offsetY - factor * (e.offsetY - view.y);
+    view.scale *= factor; draw();
+}, {passive: false});
+canvas.addEventListener("dblclick", fit);
+
+//Order slider
+if (data.grammar) {
+    const bar = document.getElementById("bar"), g = data.grammar;
+    bar.innerHTML = 'Order <input id="order" type="range" min="0" max="' + g.MAX_ORDER + '" value="' + g.ORDER +
+                    '"> <span id="value">' + g.ORDER + '</span> <span id="note"></span>';
+    document.getElementById("order").addEventListener("input", e => {
+        const order = parseInt(e.target.value), cmds = derive(order);
+        document.getElementById("value").textContent = order;
+        const geometry = cmds === null ? null : interpret(cmds, data.angle);
+        document.getElementById("note").textContent = cmds === null ? "(too many symbols)" :
+                                                      geometry === null ? "(malformed turtle commands)" : "";
+        if (geometry === null) { return; }
+        paths = geometry.paths; polygons = geometry.polygons; fit();
+    });
+}
+fit();
+</script>
+</body>
+</html>
+`
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of html.go
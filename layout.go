@@ -0,0 +1,362 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      grid layouts of subplots for gnuplot and HP-GL/2 contact sheets.
+ *  Types:
+ *      GridLayout
+ *          Rows x columns arrangement of subplots with padding and scaling options.
+ *      Scaling
+ *          Scaling mode of the subplots: Isometric, SharedIsometric or Anisometric.
+ *  Functions:
+ *      GridPlot(turtleCmds []string, turtleAngles []float64, layout GridLayout, terminalCmd, outputCmd, plotTitle string,
+ *               titles, labels []string, lineColor string, cmdsFile ...string)
+ *          Plots a set of turtle commands on a grid using gnuplot. Each subplot is scaled and centered in its cell
+ *          according to the layout's scaling mode. The underlying gnuplot commands can be saved optionally to a text file.
+ *      HpglGridPlot(turtleCmds []string, turtleAngles []float64, layout GridLayout, plotTitle string,
+ *                   titles, labels []string, penWidth float64, hpglPath string, pens ...HpglMedia)
+ *          Converts a set of turtle commands on a grid to an HP-GL/2 command set. Each subplot is scaled and centered in
+ *          its cell according to the layout's scaling mode. The pens can be set optionally, as for HpglGridPlotWriter.
+ *  History: v1.4.0 - October 18, 2026 - Original release.
+ *           v1.5.0 - October 18, 2026 - Added the scaling modes.
+ *           v1.7.0 - October 18, 2026 - Added a pen per HP-GL/2 subplot.
+ *           v1.8.0 - October 18, 2026 - Added pen-travel optimisation.
+ *           v1.22.0 - October 18, 2026 - Added vertex welding.
+ *           v1.24.0 - October 18, 2026 - Replaced HpglPens with the pen settings of HpglMedia.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "fmt"
+    "math"
+    "strings"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type Scaling int
+const(
+    Isometric       Scaling = iota //each subplot is isometrically scaled to fit its own area
+    SharedIsometric                //all subplots are isometrically scaled to a common scale, so sizes are comparable
+    Anisometric                    //each subplot is stretched to fill its own area
+)
+type GridLayout struct {
+    ROWS    int     //number of rows; 0 to derive it from the number of columns
+    COLUMNS int     //number of columns; 0 to derive it from the number of rows
+    PADDING float64 //blank border around each subplot as a fraction of the cell size, in [0,0.45], less with titles or labels
+    SCALING Scaling //scaling mode of the subplots
+}
+func GridPlot(turtleCmds []string, turtleAngles []float64, layout GridLayout, terminalCmd, outputCmd, plotTitle string,
+              titles, labels []string, lineColor string, cmdsFile ...string) {
+/*         Purpose : Plots a set of turtle commands on a grid using gnuplot. Each subplot is scaled and centered in its
+ *                   cell according to the layout's scaling mode. The underlying gnuplot commands can be saved optionally
+ *                   to a text file.
+ *       Arguments : turtleCmds   = slice of turtle commands.
+ *                   turtleAngles = slice of production angles in degrees.
+ *                   layout       = grid layout. If both the rows and columns are 0, the grid will be as square as possible.
+ *                   terminalCmd  = gnuplot terminal command.
+ *                   outputCmd    = gnuplot output command.
+ *                   plotTitle    = title to be centered at the top of the plot.
+ *                   titles       = slice of titles to be centered above each subplot, or nil.
+ *                   labels       = slice of labels to be centered below each subplot, or nil.
+ *                   lineColor    = color of the line segments, specified as either a name (as recognized by gnuplot)
+ *                                  or a 6-digit X11 hex rgb code prefixed with the "#" character.
+ *                   cmdsFile     = optional file path for the gnuplot commands.
+ *         Returns : None.
+ * Externals -  In : WeldVertices, _degs2rads, _turtleHistory, _turtleStatus, _validColors
+ * Externals - Out : None.
+ *       Functions : execPlot, fileWrite, geometry2Gnuplot, gnuplotPlotCmd, halt, layoutGrid, logo2Geometry, validFgColor,
+ *                   validGrid, weldGeometry
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - The subplots fill the grid row by row, from the top left cell.
+ *                   - With anisometric scaling, the cells are stretched to the aspect ratio of the canvas.
+ *         History : v1.4.0 - October 18, 2026 - Original release.
+ *                   v1.5.0 - October 18, 2026 - Added the scaling modes.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ */
+    validGrid(turtleCmds, turtleAngles, layout, titles, labels)
+    if ! validFgColor(lineColor) { halt( fmt.Sprintf("the color name '%s' is not valid. Recognized names are:\n\n%s",
+                                                     lineColor, _validColors)) }
+
+    const( minMargin    = "1"
+           maxMargin    = "2"
+    )
+    var(   tmargin      = map[bool]string{true: maxMargin, false: minMargin} [plotTitle != ""]
+           geometries   = make([]_geometry, len(turtleCmds))
+           plotCmds     []string
+           plotData     []string
+    )
+    //Initialize
+    plotCmds = append(plotCmds,
+                terminalCmd,
+                outputCmd,
+                "unset border",
+                "unset tics",
+                "set bmargin " + minMargin,
+                "set tmargin " + tmargin,
+                "set rmargin " + minMargin,
+                "set lmargin " + minMargin,
+                map[bool]string{true: "set size noratio", false: "set size ratio -1"} [layout.SCALING == Anisometric],
+                "set autoscale fix",
+                fmt.Sprintf(`set style fill solid 1.0 border rgb "%s"`, lineColor),
+                fmt.Sprintf(`set style arrow 1 nohead lc rgb "%s"`, lineColor))
+    if plotTitle != "" { plotCmds = append(plotCmds, fmt.Sprintf(`set title "%s" tc rgb "%s"`, plotTitle, lineColor)) }
+    //Interpret the turtle commands and lay out the subplots
+    for k, v := range turtleCmds {
+        geometries[k] = logo2Geometry(v, turtleAngles[k], nil)
+        weldGeometry(&geometries[k])
+    }
+    cells, columns, rows := layoutGrid(geometries, layout, titles, labels)
+    //Convert the subplots to headless arrows and polygons, along with their titles and labels
+    for k := range geometries {
+        if titles != nil && titles[k] != "" {
+            plotCmds = append(plotCmds, fmt.Sprintf(`set label "%s" at %f,%f center front tc rgb "%s"`,
+                                                    titles[k], cells[k].TITLE.X, cells[k].TITLE.Y, lineColor))
+        }
+        if labels != nil && labels[k] != "" {
+            plotCmds = append(plotCmds, fmt.Sprintf(`set label "%s" at %f,%f center front tc rgb "%s"`,
+                                                    labels[k], cells[k].LABEL.X, cells[k].LABEL.Y, lineColor))
+        }
+        drawCmds, drawData := geometry2Gnuplot(&geometries[k], cells[k].FIT, lineColor)
+        plotCmds, plotData  = append(plotCmds, drawCmds...), append(plotData, drawData...)
+    }
+    //Compose the remaining gnuplot commands
+    plotCmds = append(plotCmds,
+                fmt.Sprintf("set xrange [%f:%f]", 0., float64(columns)),
+                fmt.Sprintf("set yrange [%f:%f]", 0., float64(rows)),
+                "set parametric")
+    plotCmds = append(append(plotCmds, gnuplotPlotCmd(lineColor, plotData)...), "quit")
+    //Send the commands to the gnuplot executable
+    execPlot(terminalCmd, &plotCmds)
+    //Save the commands if requested
+    if len(cmdsFile) != 0 { fileWrite(cmdsFile[0], strings.Join(plotCmds, "\n")) }
+    return
+} //end func GridPlot
+func HpglGridPlot(turtleCmds []string, turtleAngles []float64, layout GridLayout, plotTitle string,
+                  titles, labels []string, penWidth float64, hpglPath string, pens ...HpglMedia) {
+/*         Purpose : Converts a set of turtle commands on a grid to an HP-GL/2 command set. Each subplot is scaled and
+ *                   centered in its cell according to the layout's scaling mode.
+ *       Arguments : turtleCmds   = slice of turtle commands.
+ *                   turtleAngles = slice of production angles in degrees.
+ *                   layout       = grid layout. If both the rows and columns are 0, the grid will be as square as possible.
+ *                   plotTitle    = title to be centered at the top of the plot.
+ *                   titles       = slice of titles to be centered above each subplot, or nil.
+ *                   labels       = slice of labels to be centered below each subplot, or nil.
+ *                   penWidth     = line-width in millimeters, unless the pen widths are set.
+ *                   hpglPath     = file path or device port for the HP-GL/2 commands.
+ *                   pens         = optional pen settings, of which only the pens, their assignment and their sorting
+ *                                  are used.
+ *         Returns : None.
+ * Externals -  In : OptimizePenTravel, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : PenTravel
+ *       Functions : assignStrahler, fileWrite, geometry2Hpgl, halt, hpglPenWidths, layoutGrid, logo2Geometry,
+ *                   makePenSelector, resetPenTravel, strokes2Hpgl, validGrid, validPenSettings, weldGeometry
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - The subplots fill the grid row by row, from the top left cell. Grids wider than they are tall
+ *                     are plotted in landscape mode.
+ *                   - With anisometric scaling, the cells are stretched to the aspect ratio of the media.
+ *                   - If several pens are set, they are assigned to the strokes as in HpglGridPlotWriter; the titles
+ *                     and labels are drawn with Pen 1.
+ *                   - If OptimizePenTravel is set, the strokes of each subplot are merged and reordered to minimise
+ *                     the pen-up travel.
+ *         History : v1.4.0 - October 18, 2026 - Original release.
+ *                   v1.5.0 - October 18, 2026 - Added the scaling modes.
+ *                   v1.7.0 - October 18, 2026 - Added a pen per subplot.
+ *                   v1.8.0 - October 18, 2026 - Added pen-travel optimisation.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ *                   v1.24.0 - October 18, 2026 - Replaced HpglPens with the optional pen settings.
+ */
+    validGrid(turtleCmds, turtleAngles, layout, titles, labels)
+    if hpglPath == "" { halt("the path for the plot was not specified") }
+    penSettings := validPenSettings(pens)
+
+    const( esc       = 27  //Escape code
+           ext       = 3   //End of Text code
+           minMargin = 0.1 //% - prevent clipping of wide pen strokes
+           maxMargin = 3.0 //% - prevent clipping of title
+    )
+    var(   rmargin    = minMargin
+           lmargin    = 100. - minMargin
+           bmargin    = minMargin
+           tmargin    = map[bool]float64{true: 100. - maxMargin, false: 100. - minMargin} [plotTitle != ""]
+
+           geometries = make([]_geometry, len(turtleCmds))
+           plotCmds   string
+           strokes    []_hpglStroke
+    )
+    //Interpret the turtle commands and lay out the subplots
+    for k, v := range turtleCmds {
+        geometries[k] = logo2Geometry(v, turtleAngles[k], nil)
+        if penSettings.PEN_BY == PenByStrahler { assignStrahler(&geometries[k]) }
+        weldGeometry(&geometries[k])
+    }
+    cells, columns, rows := layoutGrid(geometries, layout, titles, labels)
+    //Convert the titles and labels to HP-GL/2 commands, then the subplots
+    for k := range geometries {
+        if titles != nil && titles[k] != "" {
+            plotCmds += fmt.Sprintf("PU%f,%f;LO5;LB%s%c;\n", cells[k].TITLE.X, cells[k].TITLE.Y, titles[k], ext)
+        }
+        if labels != nil && labels[k] != "" {
+            plotCmds += fmt.Sprintf("PU%f,%f;LO5;LB%s%c;\n", cells[k].LABEL.X, cells[k].LABEL.Y, labels[k], ext)
+        }
+    }
+    report := resetPenTravel()
+    for k := range geometries {
+        strokes = append(strokes, geometry2Hpgl(&geometries[k], cells[k].FIT, 6,
+                                                makePenSelector(penSettings, k), report)...)
+    }
+    plotCmds += strokes2Hpgl(strokes, penSettings.PEN_SORT)
+    //Compose the remaining HP-GL/2 commands
+    plotCmds = //HP RTL: enter HP-GL/2 mode, begin a plot and initialize HP-GL/2
+               fmt.Sprintf("%c%%-1BBPIN;\n", esc) +
+               //set the orientation to landscape for wide grids
+               map[bool]string{true: "RO90;\n", false: ""} [columns > rows] +
+               //set the margins for the drawing
+               fmt.Sprintf("IR%f,%f,%f,%f;\n", rmargin, bmargin, lmargin, tmargin) +
+               //set the scaling as isotropic unless anisometric subplots are requested
+               fmt.Sprintf("SC%f,%f,%f,%f,%d;\n", 0., float64(columns), 0., float64(rows),
+                           map[bool]int{true: 0, false: 1} [layout.SCALING == Anisometric]) +
+               //select Pen 1 (black) and set the pen widths in millimeters
+               hpglPenWidths(penWidth, penSettings.PENS) +
+               //add the previous subplot commands
+               plotCmds
+    if plotTitle != "" {
+        plotCmds += //reset the margin settings for the title
+                    fmt.Sprintf("IR;IR%f,%f,%f,%f;\n", rmargin, bmargin, lmargin, 100. - minMargin) +
+                    //set the scaling as anisotropic
+                    fmt.Sprintf("SC%f,%f,%f,%f,0;\n", 0., float64(columns), 0., float64(rows)) +
+                    //draw the plot title centered at the top
+                    fmt.Sprintf("PU%f,%f;LO6;LB%s%c;\n", 0.5*float64(columns), float64(rows), plotTitle, ext)
+    }
+    //end (page advance)
+    plotCmds += "PG;\n"
+    //Output the commands to the specified destination
+    fileWrite(hpglPath, plotCmds)
+    return
+} //end func HpglGridPlot
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+const _gridBand = 0.1 //fraction of the cell height reserved for a title or a label
+type _gridCell struct {
+    FIT   func(p _point) _point //transform from turtle coordinates to grid coordinates
+    TITLE _point                //anchor of the subplot title
+    LABEL _point                //anchor of the subplot label
+}
+func validGrid(turtleCmds []string, turtleAngles []float64, layout GridLayout, titles, labels []string) {
+    if len(turtleCmds)   == 0 { halt("the turtle commands were not specified") }
+    if len(turtleAngles) == 0 { halt("the turtle angles were not stated") }
+    if len(turtleAngles) < len(turtleCmds) {
+        halt("fewer turtle angles specified than the number of commands")
+    }
+    for k := range turtleCmds {
+        if turtleAngles[k] == 0. { halt("the production angle is zero") }
+    }
+    if titles != nil && len(titles) < len(turtleCmds) {
+        halt("fewer titles specified than the number of commands")
+    }
+    if labels != nil && len(labels) < len(turtleCmds) {
+        halt("fewer labels specified than the number of commands")
+    }
+    if layout.ROWS < 0 || layout.COLUMNS < 0       { halt("the grid dimensions must be non-negative") }
+    if layout.ROWS > 0 && layout.COLUMNS > 0 && layout.ROWS * layout.COLUMNS < len(turtleCmds) {
+        halt("the grid has fewer cells than the number of commands")
+    }
+    if layout.PADDING < 0. || layout.PADDING > 0.45 { halt("the padding must be in [0,0.45]") }
+    if topBand, bottomBand := gridBands(titles, labels); 1. - 2. * layout.PADDING - topBand - bottomBand <= 0. {
+        halt("the padding leaves no room for the subplots between the titles and the labels")
+    }
+    if layout.SCALING < Isometric || layout.SCALING > Anisometric { halt("the scaling mode is not valid") }
+    return
+} //end func validGrid
+func gridBands(titles, labels []string) (topBand, bottomBand float64) {
+    //a band is reserved at the top (bottom) of every cell when any title (label) is specified
+    topBand    = map[bool]float64{true: _gridBand, false: 0.} [strings.Join(titles, "") != ""]
+    bottomBand = map[bool]float64{true: _gridBand, false: 0.} [strings.Join(labels, "") != ""]
+    return
+} //end func gridBands
+func layoutGrid(geometries []_geometry, layout GridLayout, titles, labels []string) (cells []_gridCell,
+                                                                                     columns, rows int) {
+/*         Purpose : Lays out subplots on a grid of unit cells.
+ *       Arguments : geometries = subplot geometries.
+ *                   layout     = grid layout.
+ *                   titles     = slice of subplot titles, or nil.
+ *                   labels     = slice of subplot labels, or nil.
+ *         Returns : the cell of each subplot and the grid dimensions.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : gridBands
+ *         Remarks : - Grid coordinates have their origin at the bottom left corner of the grid.
+ *                   - A band is reserved at the top (bottom) of every cell when any title (label) is specified.
+ *                   - validGrid ensures that the padding leaves room for the drawing box between the bands.
+ *         History : v1.4.0 - October 18, 2026 - Original release.
+ *                   v1.5.0 - October 18, 2026 - Added the scaling modes.
+ */
+    var( numPlots            = len(geometries)
+         scales              = make([]float64, numPlots)
+         topBand, bottomBand = gridBands(titles, labels)
+    )
+    //Set the grid dimensions
+    columns, rows = layout.COLUMNS, layout.ROWS
+    switch {
+        case columns == 0 && rows == 0:
+            columns = int(math.Ceil(math.Sqrt(float64(numPlots))))
+            rows    = (numPlots + columns - 1) / columns
+        case columns == 0:
+            columns = (numPlots + rows - 1) / rows
+        case rows == 0:
+            rows    = (numPlots + columns - 1) / columns
+    }
+    //Compute the drawing box of a cell relative to its bottom left corner
+    boxWidth  := 1. - 2. * layout.PADDING
+    boxHeight := 1. - 2. * layout.PADDING - topBand - bottomBand
+    boxX      := layout.PADDING
+    boxY      := layout.PADDING + bottomBand
+    //Compute the scale fitting each subplot to the drawing box
+    minScale := math.Inf(1)
+    for k, v := range geometries {
+        xSpan, ySpan := math.Max(v.XMAX - v.XMIN, 1e-9), math.Max(v.YMAX - v.YMIN, 1e-9)
+        scales[k]     = math.Min(boxWidth / xSpan, boxHeight / ySpan)
+        minScale      = math.Min(minScale, scales[k])
+    }
+    //Position each subplot in its cell, filling the grid row by row from the top
+    for k, v := range geometries {
+        column, row    := float64(k % columns), float64(rows - 1 - k / columns)
+        xScale, yScale := scales[k], scales[k]
+        switch layout.SCALING {
+            case SharedIsometric:
+                xScale, yScale = minScale, minScale
+            case Anisometric:
+                xScale, yScale = boxWidth / math.Max(v.XMAX - v.XMIN, 1e-9), boxHeight / math.Max(v.YMAX - v.YMIN, 1e-9)
+        }
+        xShift := column + boxX + 0.5 * (boxWidth  - xScale * (v.XMAX - v.XMIN)) - xScale * v.XMIN
+        yShift := row    + boxY + 0.5 * (boxHeight - yScale * (v.YMAX - v.YMIN)) - yScale * v.YMIN
+        cells = append(cells, _gridCell{
+                    FIT:   func(p _point) _point { return _point{xShift + xScale * p.X, yShift + yScale * p.Y} },
+                    TITLE: _point{column + 0.5, row + 1. - layout.PADDING - 0.5 * topBand},
+                    LABEL: _point{column + 0.5, row + layout.PADDING + 0.5 * bottomBand}})
+    }
+    return
+} //end func layoutGrid
+func geometry2Gnuplot(geometry *_geometry, fit func(p _point) _point, lineColor string) (plotCmds, plotData []string) {
+    for _, polygon := range geometry.POLYGONS {
+        start := fit(polygon.VERTICES[0])
+        cmd   := fmt.Sprintf(`set object polygon fc rgb "%s" from %f,%f`, lineColor, start.X, start.Y)
+        for _, vertex := range polygon.VERTICES[1:] {
+            to   := fit(vertex)
+            cmd  += fmt.Sprintf(" to %f,%f", to.X, to.Y)
+        }
+        plotCmds = append(plotCmds, cmd)
+    }
+    if WeldVertices { //welded segments are plotted as inline polylines
+        plotData = polylines2Gnuplot(chainSegments(geometry.SEGMENTS), fit)
+        return
+    }
+    for _, segment := range geometry.SEGMENTS {
+        from, to := fit(segment.FROM), fit(segment.TO)
+        plotCmds  = append(plotCmds, fmt.Sprintf("set arrow as 1 from %f,%f to %f,%f", from.X, from.Y, to.X, to.Y))
+    }
+    return
+} //end func geometry2Gnuplot
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of layout.go
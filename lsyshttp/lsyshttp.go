@@ -0,0 +1,322 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsyshttp
+ *  Overview:
+ *      net/http handler rendering L-systems on demand as SVG, PNG or HP-GL/2.
+ *  Types:
+ *      Limits
+ *          Bounds on the work done for a single request.
+ *      Spec
+ *          Rendering request: production parameters, output format and size, and interpretation settings.
+ *  Variables:
+ *      DefaultLimits Limits
+ *          Limits suited to an internal service: order 16, 4 million symbols, 4096 pixels, pen-travel optimisation of
+ *          plots of up to 1 million symbols.
+ *  Functions:
+ *      NewHandler(limits Limits, cacheSize int) http.Handler
+ *          Returns a handler rendering the specs received as JSON POST bodies or GET query strings, caching the results
+ *          by spec hash.
+ *  Remarks:
+ *      A GET query names the fields as in the JSON encoding, with one "rule" parameter per production rule written as
+ *      predecessor=successor, e.g., /?axiom=F&rule=F%3DF%2BF--F%2BF&angle=60&order=4&format=svg. Note that "+" must
+ *      be escaped as %2B, since query strings decode it as a space.
+ *      The handler relies on the error-returning functions of the lsystems package and recovers from any panic, so
+ *      that a bad request is answered with an error status instead of stopping the server. The interpretation settings
+ *      are taken from each spec rather than from the package variables of lsystems.
+ *  History: v1.14.0 - October 18, 2026 - Original release.
+ *           v1.24.0 - October 18, 2026 - Added the interpretation settings to the spec and the optimisation limit.
+ *============================================================================================================================*/
+package lsyshttp
+
+import(
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "github.com/ybeaudoin/go-lsystems"
+    "io"
+    "log"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+    "sync"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type Limits struct {
+    MAX_ORDER     int //highest derivation order
+    MAX_SYMBOLS   int //most turtle commands in any derivation step
+    MAX_SIZE      int //largest image width or height in pixels
+    MAX_OPTIMIZED int //most turtle commands of an HP-GL/2 plot whose pen travel is optimised; 0 to refuse optimisation
+}
+type Spec struct {
+    AXIOM    string            `json:"axiom"`
+    RULES    map[string]string `json:"rules"`    //production rules keyed by their predecessor
+    ANGLE    float64           `json:"angle"`    //production angle in degrees
+    ORDER    int               `json:"order"`
+    FORMAT   string            `json:"format"`   //"svg" (default), "png" or "hpgl"
+    WIDTH    int               `json:"width"`    //image width in pixels; 0 for 512
+    HEIGHT   int               `json:"height"`   //image height in pixels; 0 for the width
+    EXACT    bool              `json:"exact"`    //interpret exactly whenever the headings divide the full turn
+    WELD     bool              `json:"weld"`     //weld the vertices and merge the line segments into polylines
+    OPTIMIZE bool              `json:"optimize"` //minimise the pen-up travel of HP-GL/2 plots
+}
+var DefaultLimits = Limits{MAX_ORDER: 16, MAX_SYMBOLS: 4000000, MAX_SIZE: 4096, MAX_OPTIMIZED: 1000000}
+func NewHandler(limits Limits, cacheSize int) http.Handler {
+/*         Purpose : Returns a handler rendering the specs received as JSON POST bodies or GET query strings, caching the
+ *                   results by spec hash.
+ *       Arguments : limits    = bounds on the work done for a single request.
+ *                   cacheSize = most renderings kept in memory, or 0 for no caching.
+ *         Returns : the handler.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : - HP-GL/2 plots are made on A4 media, in landscape orientation if the width exceeds the height.
+ *                   - Responses carry the spec hash as their ETag, so that clients may revalidate with If-None-Match.
+ *                   - The status is 400 for a malformed spec, 405 for a method other than GET or POST, 422 for a spec
+ *                     exceeding the limits and 500 for a failed rendering.
+ *         History : v1.14.0 - October 18, 2026 - Original release.
+ */
+    if cacheSize < 0 { cacheSize = 0 }
+    return &_handler{LIMITS: limits, CACHE: &_cache{SIZE: cacheSize, ENTRIES: make(map[string]_rendering)}}
+} //end func NewHandler
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+const _maxBodyBytes = 1 << 20 //largest JSON spec accepted
+var _contentTypes = map[string]string{"svg": "image/svg+xml", "png": "image/png", "hpgl": "application/vnd.hp-hpgl"}
+type _handler struct {
+    LIMITS Limits
+    CACHE  *_cache
+}
+type _cache struct { //renderings evicted in insertion order
+    MUTEX   sync.Mutex
+    SIZE    int
+    ENTRIES map[string]_rendering
+    KEYS    []string
+}
+type _rendering struct {
+    CONTENT_TYPE string
+    BODY         []byte
+}
+type _specError struct { //error to be reported with a given HTTP status
+    STATUS int
+    MSG    string
+}
+func(err *_specError) Error() string {
+    return err.MSG
+} //end func Error
+func(handler *_handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+/*         Purpose : Serves a rendering request.
+ *       Arguments : writer  = response writer.
+ *                   request = GET request with the spec as its query string, or POST request with the spec as its JSON
+ *                             body.
+ *         Returns : None.
+ * Externals -  In : _contentTypes, _maxBodyBytes
+ * Externals - Out : None.
+ *       Functions : checkSpec, parseQuery, render, specHash
+ *         Remarks : None.
+ *         History : v1.14.0 - October 18, 2026 - Original release.
+ */
+    var spec Spec
+    //Guard the server against any unforeseen failure
+    defer func() {
+        if r := recover(); r != nil {
+            log.Printf("lsyshttp: %s %s: %v", request.Method, request.URL, r)
+            http.Error(writer, "the rendering failed", http.StatusInternalServerError)
+        }
+    }()
+    //Decode the spec
+    switch request.Method {
+        case http.MethodGet, http.MethodHead:
+            var err error
+            if spec, err = parseQuery(request.URL.Query()); err != nil {
+                http.Error(writer, err.Error(), http.StatusBadRequest)
+                return
+            }
+        case http.MethodPost:
+            decoder := json.NewDecoder(io.LimitReader(request.Body, _maxBodyBytes))
+            decoder.DisallowUnknownFields()
+            if err := decoder.Decode(&spec); err != nil {
+                http.Error(writer, "the spec is not valid JSON - " + err.Error(), http.StatusBadRequest)
+                return
+            }
+        default:
+            writer.Header().Set("Allow", "GET, HEAD, POST")
+            http.Error(writer, "the method is not allowed", http.StatusMethodNotAllowed)
+            return
+    }
+    if err := checkSpec(&spec, handler.LIMITS); err != nil {
+        http.Error(writer, err.Error(), err.STATUS)
+        return
+    }
+    //Answer from the cache or render
+    hash := specHash(spec)
+    etag := `"` + hash + `"`
+    if request.Header.Get("If-None-Match") == etag {
+        writer.WriteHeader(http.StatusNotModified)
+        return
+    }
+    rendering, ok := handler.CACHE.get(hash)
+    if !ok {
+        body, err := render(spec, handler.LIMITS)
+        if err != nil {
+            status := http.StatusInternalServerError
+            var specErr *_specError
+            if errors.As(err, &specErr) { status = specErr.STATUS }
+            http.Error(writer, err.Error(), status)
+            return
+        }
+        rendering = _rendering{_contentTypes[spec.FORMAT], body}
+        handler.CACHE.put(hash, rendering)
+    }
+    writer.Header().Set("Content-Type", rendering.CONTENT_TYPE)
+    writer.Header().Set("Content-Length", strconv.Itoa(len(rendering.BODY)))
+    writer.Header().Set("ETag", etag)
+    writer.Header().Set("Cache-Control", "public, max-age=86400")
+    if request.Method != http.MethodHead { writer.Write(rendering.BODY) }
+    return
+} //end func ServeHTTP
+func(cache *_cache) get(key string) (rendering _rendering, ok bool) {
+    cache.MUTEX.Lock()
+    defer cache.MUTEX.Unlock()
+    rendering, ok = cache.ENTRIES[key]
+    return
+} //end func get
+func(cache *_cache) put(key string, rendering _rendering) {
+    cache.MUTEX.Lock()
+    defer cache.MUTEX.Unlock()
+    if cache.SIZE == 0 { return }
+    if _, ok := cache.ENTRIES[key]; ok { return } //rendered concurrently by another request
+    if len(cache.KEYS) == cache.SIZE {
+        delete(cache.ENTRIES, cache.KEYS[0])
+        cache.KEYS = cache.KEYS[1:]
+    }
+    cache.ENTRIES[key] = rendering
+    cache.KEYS         = append(cache.KEYS, key)
+    return
+} //end func put
+func parseQuery(query url.Values) (spec Spec, err error) {
+/*         Purpose : Decodes a spec from a query string.
+ *       Arguments : query = parsed query string.
+ *         Returns : the spec, or an error.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : Unknown parameters are rejected, as are unknown JSON fields.
+ *         History : v1.14.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Added the interpretation settings.
+ */
+    number := func(name string, value *int) {
+        if text := query.Get(name); text != "" && err == nil {
+            if *value, err = strconv.Atoi(text); err != nil { err = fmt.Errorf("the %s is not an integer", name) }
+        }
+    }
+    flag := func(name string, value *bool) {
+        if text := query.Get(name); text != "" && err == nil {
+            if *value, err = strconv.ParseBool(text); err != nil { err = fmt.Errorf("the %s flag is not a boolean", name) }
+        }
+    }
+    for name := range query {
+        switch name {
+            case "axiom", "rule", "angle", "order", "format", "width", "height", "exact", "weld", "optimize":
+            default: return spec, fmt.Errorf("the parameter %q is unknown", name)
+        }
+    }
+    spec.AXIOM  = query.Get("axiom")
+    spec.FORMAT = query.Get("format")
+    spec.RULES  = make(map[string]string)
+    for _, rule := range query["rule"] {
+        predecessor, successor, found := strings.Cut(rule, "=")
+        if !found { return spec, fmt.Errorf("the rule %q is not of the form predecessor=successor", rule) }
+        spec.RULES[predecessor] = successor
+    }
+    if text := query.Get("angle"); text != "" {
+        if spec.ANGLE, err = strconv.ParseFloat(text, 64); err != nil { return spec, errors.New("the angle is not a number") }
+    }
+    number("order", &spec.ORDER)
+    number("width", &spec.WIDTH)
+    number("height", &spec.HEIGHT)
+    flag("exact", &spec.EXACT)
+    flag("weld", &spec.WELD)
+    flag("optimize", &spec.OPTIMIZE)
+    return
+} //end func parseQuery
+func checkSpec(spec *Spec, limits Limits) *_specError {
+/*         Purpose : Validates a spec against the limits and fills in its defaults.
+ *       Arguments : spec   = spec to be completed.
+ *                   limits = bounds on the work done for a single request.
+ *         Returns : nil, or the error to report.
+ * Externals -  In : _contentTypes
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : The symbol limits are enforced during the derivation and ahead of the pen-travel optimisation.
+ *         History : v1.14.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Refused the pen-travel optimisation when the limits disable it.
+ */
+    invalid := func(msg string) *_specError { return &_specError{http.StatusBadRequest, msg} }
+    if spec.FORMAT == "" { spec.FORMAT = "svg" }
+    if spec.WIDTH  == 0  { spec.WIDTH = 512 }
+    if spec.HEIGHT == 0  { spec.HEIGHT = spec.WIDTH }
+    if spec.AXIOM  == "" { return invalid("the axiom was not specified") }
+    for predecessor := range spec.RULES {
+        if predecessor == "" { return invalid("a rule has no predecessor") }
+    }
+    if spec.ANGLE  == 0. { return invalid("the angle is zero or was not specified") }
+    if spec.ORDER < 0    { return invalid("the order must be non-negative") }
+    if _, ok := _contentTypes[spec.FORMAT]; !ok { return invalid(fmt.Sprintf("the format %q is not supported", spec.FORMAT)) }
+    if spec.WIDTH < 1 || spec.HEIGHT < 1 { return invalid("the size must be positive") }
+    if spec.ORDER > limits.MAX_ORDER {
+        return &_specError{http.StatusUnprocessableEntity, fmt.Sprintf("the order exceeds %d", limits.MAX_ORDER)}
+    }
+    if spec.WIDTH > limits.MAX_SIZE || spec.HEIGHT > limits.MAX_SIZE {
+        return &_specError{http.StatusUnprocessableEntity, fmt.Sprintf("the size exceeds %d pixels", limits.MAX_SIZE)}
+    }
+    if spec.OPTIMIZE && spec.FORMAT == "hpgl" && limits.MAX_OPTIMIZED < 1 {
+        return &_specError{http.StatusUnprocessableEntity, "the pen-travel optimisation is disabled"}
+    }
+    return nil
+} //end func checkSpec
+func specHash(spec Spec) string {
+    //json.Marshal sorts the map keys, so that equal specs have equal encodings
+    encoded, _ := json.Marshal(spec)
+    sum        := sha256.Sum256(encoded)
+    return hex.EncodeToString(sum[:])
+} //end func specHash
+func render(spec Spec, limits Limits) ([]byte, error) {
+/*         Purpose : Derives and renders a validated spec.
+ *       Arguments : spec   = validated spec.
+ *                   limits = bounds on the work done for a single request.
+ *         Returns : the rendering, or an error.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : lsystems.Derive, lsystems.RenderHpgl, lsystems.RenderPng, lsystems.RenderSvg
+ *         Remarks : - Derivations exceeding the symbol limit, and plots to optimise exceeding the optimisation limit, are
+ *                     reported with status 422, malformed turtle commands with status 400.
+ *                   - The vertices are welded within the default tolerance.
+ *         History : v1.14.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Passed the interpretation settings of the spec and enforced the
+ *                                                optimisation limit.
+ */
+    turtleCmds, err := lsystems.Derive(spec.ORDER, spec.AXIOM, spec.RULES, limits.MAX_SYMBOLS)
+    if err != nil { return nil, &_specError{http.StatusUnprocessableEntity, err.Error()} }
+    if spec.OPTIMIZE && spec.FORMAT == "hpgl" && len(turtleCmds) > limits.MAX_OPTIMIZED {
+        return nil, &_specError{http.StatusUnprocessableEntity,
+                                fmt.Sprintf("the plot to optimise exceeds %d symbols", limits.MAX_OPTIMIZED)}
+    }
+    var body []byte
+    options := lsystems.RenderOptions{EXACT_TURTLE: spec.EXACT, OPTIMIZE_TRAVEL: spec.OPTIMIZE, WELD_VERTICES: spec.WELD}
+    switch spec.FORMAT {
+        case "svg":
+            body, err = lsystems.RenderSvg(turtleCmds, spec.ANGLE, spec.WIDTH, spec.HEIGHT, 1., "black", "white", options)
+        case "png":
+            body, err = lsystems.RenderPng(turtleCmds, spec.ANGLE, spec.WIDTH, spec.HEIGHT, 1, nil, options)
+        case "hpgl":
+            media          := lsystems.MediaA4
+            media.LANDSCAPE = spec.WIDTH > spec.HEIGHT
+            body, err = lsystems.RenderHpgl(turtleCmds, spec.ANGLE, "", 0.35, media, options)
+    }
+    if err != nil { return nil, &_specError{http.StatusBadRequest, err.Error()} }
+    return body, nil
+} //end func render
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of lsyshttp.go
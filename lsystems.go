@@ -6,9 +6,18 @@
  *  Variables:
  *      TurtleCmds string
  *          Generated turtle-graphics commands
+ *      MultiPlotScaling Scaling
+ *          Scaling mode of the subplots generated by MultiPlot and HpglMultiPlot: Anisometric (default), Isometric
+ *          or SharedIsometric.
+ *      ProgressBars bool
+ *          Displays progress bars on the standard output (default: true). Disable when the standard output is the
+ *          destination of a plot.
  *  Functions:
  *      Deterministic(order int, axiom string, rules *strings.Replacer)
  *          Generates the required turtle commands for the specified deterministic and context-free production parameters.
+ *      DeterministicPairs(order int, axiom string, oldnew ...string)
+ *          Generates the required turtle commands for the specified deterministic and context-free production rules,
+ *          given as predecessor and successor pairs, deriving them in parallel.
  *      Stochastic(order int, axiom string, rules []string, weights []int)
  *          Generates the required turtle commands for the specified stochastic and context-free production parameters.
  *      HogewegHesper(order int, axiom string, rules map[string]string)
@@ -22,15 +31,17 @@
  *          isometrically scaled and centered. The underlying gnuplot commands can be saved optionally to a text file.
  *      MultiPlot(turtleCmds []string, turtleAngles []float64, terminalCmd, outputCmd, plotTitle string, labels []string,
  *                lineColor string, cmdsFile ...string)
- *          Plots a set of turtle commands with the given parameters using gnuplot. The result will be anisometrically scaled
- *          with the subplots generated left to right. The underlying gnuplot commands can be saved optionally to a text file.
+ *          Plots a set of turtle commands with the given parameters using gnuplot. The result will be scaled according to
+ *          MultiPlotScaling with the subplots generated left to right. The underlying gnuplot commands can be saved
+ *          optionally to a text file.
  *      HpglPlot(angle float64, plotTitle string, penWidth float64, hpglPath string)
  *          Converts the latest generated turtle commands with the given parameters to an HP-GL/2 command set.
  *          The resulting plot will be isometrically scaled and centered.
  *      HpglMultiPlot(turtleCmds []string, turtleAngles []float64, plotTitle string, labels []string,
- *                    penWidth float64, hpglPath string)
+ *                    penWidth float64, hpglPath string, pens ...HpglMedia)
  *          Converts a set of turtle commands with the given parameters to an HP-GL/2 command set.
- *          The resulting plot will be anisometrically scaled with the subplots generated left to right in landscape mode.
+ *          The resulting plot will be scaled according to MultiPlotScaling with the subplots generated left to right in
+ *          landscape mode. The pens can be set optionally, as for HpglPlotWriter.
  *  Remarks: L-system symbols:
  *            Variables : any symbol that does not conflict with the constants below,
  *            Constants : F f + - | $ ( ) [ ] { }
@@ -49,12 +60,23 @@
  *                "}" ends polygon mode.
  *              All other symbols will be ignored during drawing.
  *  History: v1.0.0 - September 28, 2016 - Original release.
+ *           v1.5.0 - October 18, 2026 - Added MultiPlotScaling.
+ *           v1.6.0 - October 18, 2026 - Added ProgressBars.
+ *           v1.7.0 - October 18, 2026 - Added HpglPens.
+ *           v1.21.0 - October 18, 2026 - Added the exact turtle to the gnuplot and HP-GL/2 interpretations.
+ *           v1.22.0 - October 18, 2026 - Added vertex welding to the gnuplot and HP-GL/2 plots.
+ *           v1.23.0 - October 18, 2026 - Added parallel derivation to Stochastic and HogewegHesper.
+ *                                        Added DeterministicPairs.
+ *           v1.24.0 - October 18, 2026 - Derived and interpreted the turtle commands as module arrays, formatting
+ *                                        TurtleCmds once derived.
+ *                                        Replaced HpglPens with the pen settings of HpglMedia.
  *============================================================================================================================*/
 package lsystems
 
 import(
     "bitbucket.org/binet/go-gnuplot/pkg/gnuplot"
     "fmt"
+    "io"
     "log"
     "math"
     "math/rand"
@@ -63,12 +85,14 @@ import(
     "regexp"
     "runtime"
     "sort"
-    "strconv"
     "strings"
     "time"
 )
 /*Exported -------------------------------------------------------------------------------------------------------------------*/
-var TurtleCmds string //generated turtle commands
+var( TurtleCmds       string        //generated turtle commands
+     MultiPlotScaling = Anisometric  //scaling mode of the subplots generated by MultiPlot and HpglMultiPlot
+     ProgressBars     = true         //display progress bars on the standard output
+)
 
 func Deterministic(order int, axiom string, rules *strings.Replacer) {
 /*         Purpose : Generates the required turtle commands for the specified deterministic and context-free production
@@ -83,6 +107,9 @@ func Deterministic(order int, axiom string, rules *strings.Replacer) {
  *       Functions : halt
  *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
  *                   - Pseudo-L-systems are supported.
+ *                   - The derivation is serial and rewrites strings, since a strings.Replacer neither reveals its
+ *                     predecessors to the chunk cuts nor rewrites module arrays. DeterministicPairs is its parallel
+ *                     counterpart.
  *         History : v1.0.0 - September 28, 2016 - Original release.
  */
     if order < 0   { halt("curve order must be non-negative") }
@@ -95,6 +122,38 @@ func Deterministic(order int, axiom string, rules *strings.Replacer) {
     }
     return
 } //end func Deterministic
+func DeterministicPairs(order int, axiom string, oldnew ...string) {
+/*         Purpose : Generates the required turtle commands for the specified deterministic and context-free production
+ *                   parameters, given as predecessor and successor pairs.
+ *       Arguments : order  = order of the curve, that is, the derivation length of the production rules.
+ *                            (The zeroth order corresponds to the axiom.)
+ *                   axiom  = production axiom.
+ *                   oldnew = production rules as predecessor and successor pairs, as for strings.NewReplacer.
+ *         Returns : None.
+ * Externals -  In : None.
+ * Externals - Out : TurtleCmds
+ *       Functions : derivePairs, halt
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                   - Pseudo-L-systems are supported. At each position, the first rule whose predecessor matches is
+ *                     applied, as by Deterministic with strings.NewReplacer(oldnew...).
+ *                   - Each derivation step is shared among DerivationWorkers goroutines.
+ *                   - The derivation rewrites module arrays: unlike Deterministic, the heading declarations are single
+ *                     modules, formatted in their shortest form in TurtleCmds.
+ *         History : v1.23.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Rewrote module arrays.
+ */
+    if order < 0   { halt("curve order must be non-negative") }
+    if axiom == "" { halt("axiom was not specified") }
+    if len(oldnew) == 0 || len(oldnew) % 2 != 0 { halt("the rules were not specified correctly") }
+    for k := 0; k < len(oldnew); k += 2 {
+        if oldnew[k] == "" { halt("the rules were not specified correctly") }
+    }
+
+    //Apply the production rules
+    cmds, err := derivePairs(order, axiom, oldnew, 0)
+    if err != nil { halt(err.Error()) }
+    TurtleCmds = cmds
+} //end func DeterministicPairs
 func Stochastic(order int, axiom string, rules []string, weights []int) {
 /*         Purpose : Generates the required turtle commands for the specified stochastic and context-free production
  *                   parameters.
@@ -104,12 +163,19 @@ func Stochastic(order int, axiom string, rules []string, weights []int) {
  *                   rules   = slice of production rules for the constant "F".
  *                   weights = slice of weights for the production rules governing their chances of being chosen.
  *         Returns : None.
- * Externals -  In : None.
+ * Externals -  In : DerivationSeed
  * Externals - Out : TurtleCmds
- *       Functions : halt
+ *       Functions : chunkCuts, chunkRand, copy, halt, literalModules, newDerivation, step, turtleCmds, write
  *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
  *                   - Only supports rules that rewrite the constant "F".
+ *                   - Each derivation step is shared among DerivationWorkers goroutines. A non-zero DerivationSeed
+ *                     yields the same derivation regardless of their number.
+ *                   - The derivation rewrites module arrays, the heading declarations being formatted in their
+ *                     shortest form in TurtleCmds.
  *         History : v1.0.0 - September 28, 2016 - Original release.
+ *                   v1.23.0 - October 18, 2026 - Added parallel derivation with per-chunk random streams, built in
+ *                                                linear time.
+ *                   v1.24.0 - October 18, 2026 - Rewrote module arrays.
  */
     if order        < 0   { halt("curve order must be non-negative") }
     if axiom        == "" { halt("axiom was not specified") }
@@ -127,20 +193,39 @@ func Stochastic(order int, axiom string, rules []string, weights []int) {
             selectors = append(selectors, k)
         }
     }
+    successors := make([]_modules, len(rules))
+    for k, rule := range rules {
+        successors[k] = literalModules(rule)
+    }
     //Apply the production rules
-    numSelectors := len(selectors)
-    TurtleCmds    = axiom
+    var( numSelectors = len(selectors)
+         seed         = DerivationSeed
+         derivation   = newDerivation(axiom, 0)
+    )
+    if seed == 0 { seed = rand.Int63() }
     for n := 1; n <= order; n++ {
-        newCmds := ""
-        for _, symbol := range strings.Split(TurtleCmds, "") {
-            if symbol == "F" {
-                newCmds += rules[selectors[rand.Intn(numSelectors)]]
-            } else {
-                newCmds += symbol
-            }
-        }
-        TurtleCmds = newCmds
+        source := &derivation.SOURCE
+        cuts   := chunkCuts(len(source.SYMBOLS), nil)
+        err    := derivation.step(cuts, func(chunk, lo, hi, slab int, writer *_moduleWriter) error {
+                      random := chunkRand(seed, n, len(source.SYMBOLS), chunk)
+                      run, runSlab := lo, slab //start of the run of modules up to the next "F"
+                      for pos := lo; pos < hi; pos++ {
+                          switch source.SYMBOLS[pos] {
+                              case '(':
+                                  slab++
+                              case 'F':
+                                  if err := writer.copy(source, run, pos, runSlab); err != nil { return err }
+                                  if err := writer.write(&successors[selectors[random.Intn(numSelectors)]]); err != nil {
+                                      return err
+                                  }
+                                  run, runSlab = pos + 1, slab
+                          }
+                      }
+                      return writer.copy(source, run, hi, runSlab)
+                  })
+        if err != nil { halt(err.Error()) }
     }
+    TurtleCmds = derivation.SOURCE.turtleCmds()
     return
 } //end func Stochastic
 func HogewegHesper(order int, axiom string, rules map[string]string) {
@@ -153,7 +238,7 @@ func HogewegHesper(order int, axiom string, rules map[string]string) {
  *         Returns : None.
  * Externals -  In : None.
  * Externals - Out : TurtleCmds
- *       Functions : getContextLHS, getContextRHS, halt
+ *       Functions : halt, literalModules, newDerivation, rewriteContexts, turtleCmds
  *         Remarks : - Supported L-system constants are F + - $ [ ]
  *                   - Variables are "0" and "1"
  *                   - The format for each rule is "L < a > R" : "replacemnt" where "L" denotes the left context, "a" the strict
@@ -164,7 +249,12 @@ func HogewegHesper(order int, axiom string, rules map[string]string) {
  *                     (Prusinkiewicz, P. and Hanan, J. (2013) "Lindenmayer Systems, Fractals, and Plants", Volume 79 of Lecture
  *                     Notes in Biomathematics, Springer Science & Business Media, Springer Science & Business Media,
  *                     ISBN 1475714289, 9781475714289, p.42)
+ *                   - Each derivation step is shared among DerivationWorkers goroutines, each searching the contexts
+ *                     within its own chunk from the bracket contexts at the chunk's ends.
+ *                   - The branches must be balanced.
  *         History : v1.0.0 - September 28, 2016 - Original release.
+ *                   v1.23.0 - October 18, 2026 - Added parallel derivation with chunk-local context searches.
+ *                   v1.24.0 - October 18, 2026 - Rewrote module arrays.
  */
     if order < 0   { halt("curve order must be non-negative") }
     if axiom == "" { halt("axiom was not specified") }
@@ -174,43 +264,16 @@ func HogewegHesper(order int, axiom string, rules map[string]string) {
          }
     }
 
-    var( chan4LHS = make(chan string) //io channel for Coroutine getContextLHS
-         chan4RHS = make(chan string) //io channel for Coroutine getContextRHS
-    )
-    //Initialize
-    go getContextLHS(chan4LHS) //launch coroutines to find the l-h-s and r-h-s contexts respectively
-    go getContextRHS(chan4RHS)
+    var successors [3][2][3]_modules //indexed by the left context, the symbol and the right context
+    for k, v := range rules {
+        successors[k[0]-'0'+1][k[4]-'0'][k[8]-'0'+1] = literalModules(v)
+    }
     //Apply the production rules
-    TurtleCmds = axiom
+    derivation := newDerivation(axiom, 0)
     for n := 1; n <= order; n++ {
-        newCmds := ""
-        for pos := 0; pos < len(TurtleCmds); pos++ {
-            symbol := string(TurtleCmds[pos])
-            switch symbol  {
-                case "F", "[", "]", "$":
-                    newCmds += symbol
-                case "+":
-                    newCmds += "-"
-                case "-":
-                    newCmds += "+"
-                case "0", "1":
-                    chan4LHS<- TurtleCmds[:pos]
-                    chan4RHS<- TurtleCmds[pos+1:]
-                    context := fmt.Sprintf("%s < %s > %s", <-chan4LHS, symbol, <-chan4RHS)
-                    if replacement, ok := rules[context]; ok {
-                        newCmds += replacement
-                    } else {
-                        newCmds += symbol
-                    }
-                default:
-                    halt(fmt.Sprintf("the symbol '%s' is not supported", symbol))
-            }
-        }
-        TurtleCmds = newCmds
+        if err := rewriteContexts(derivation, &successors); err != nil { halt(err.Error()) }
     }
-    //Terminate coroutines
-    close(chan4LHS)
-    close(chan4RHS)
+    TurtleCmds = derivation.SOURCE.turtleCmds()
 } //end func HogewegHesper
 func EncodeBgColorName(bgColorName string) string {
 /*         Purpose : Encodes a color name into an hex string, prefixed with the character "x", for use as the specification
@@ -238,13 +301,16 @@ func Plot(angle float64, terminalCmd, outputCmd, plotTitle, lineColor string, cm
  *                                 or a 6-digit X11 hex rgb code prefixed with the "#" character.
  *                   cmdsFile    = optional file path for the gnuplot commands.
  *         Returns : None.
- * Externals -  In : TurtleCmds, _degs2rads, _turtleHistory, _turtleStatus, _validColors
- * Externals - Out : TurtleCmds
- *       Functions : execPlot, fileWrite, halt, makeLogo2Gnuplot, validFgColor
+ * Externals -  In : TurtleCmds, WeldVertices, _degs2rads, _turtleHistory, _turtleStatus, _validColors
+ * Externals - Out : None.
+ *       Functions : execPlot, fileWrite, geometry2Gnuplot, gnuplotPlotCmd, halt, logo2Geometry, makeLogo2Gnuplot,
+ *                   validFgColor, weldGeometry
  *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
  *                     All other symbols will be ignored.
  *                   - The default turtle heading is 0 degrees.
  *         History : v1.0.0 - September 28, 2016 - Original release.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ *                   v1.24.0 - October 18, 2026 - Left TurtleCmds unchanged.
  */
     if TurtleCmds == ""          { halt("the turtle commands were not generated") }
     if angle      == 0.          { halt("the production angle is zero") }
@@ -260,7 +326,13 @@ func Plot(angle float64, terminalCmd, outputCmd, plotTitle, lineColor string, cm
            tmargin      = map[bool]string{true: maxMargin, false: minMargin} [plotTitle != ""]
 
            logo2Gnuplot = makeLogo2Gnuplot(lineColor)
+           drawCmds     []string
            plotCmds     []string
+           plotData     []string
+           xMin         float64
+           xMax         float64
+           yMin         float64
+           yMax         float64
     )
     //Initialize
     plotCmds   = append(plotCmds,
@@ -279,7 +351,14 @@ func Plot(angle float64, terminalCmd, outputCmd, plotTitle, lineColor string, cm
                   fmt.Sprintf(`set style arrow 1 nohead lc rgb "%s"`, lineColor))
     if plotTitle != "" { plotCmds = append(plotCmds, fmt.Sprintf(`set title "%s" tc rgb "%s"`, plotTitle, lineColor)) }
    //Convert the turtle commands to headless arrows using unit turtle strides
-    drawCmds, xMin, xMax, yMin, yMax := logo2Gnuplot(&TurtleCmds, 0., angle)
+    if WeldVertices { //draw with welded vertices and merged polylines
+        geometry              := logo2Geometry(TurtleCmds, angle, nil)
+        weldGeometry(&geometry)
+        drawCmds, plotData     = geometry2Gnuplot(&geometry, func(p _point) _point { return p }, lineColor)
+        xMin, xMax, yMin, yMax = geometry.XMIN, geometry.XMAX, geometry.YMIN, geometry.YMAX
+    } else {
+        drawCmds, xMin, xMax, yMin, yMax = logo2Gnuplot(TurtleCmds, 0., angle)
+    }
     plotCmds = append(plotCmds, drawCmds...)
     //Compute offsets so as to center the plot in a square bounding box
     xSpan, ySpan := xMax - xMin, yMax - yMin
@@ -291,9 +370,8 @@ func Plot(angle float64, terminalCmd, outputCmd, plotTitle, lineColor string, cm
                 fmt.Sprintf("set xrange [%f:%f]", xMin, xMax),
                 fmt.Sprintf("set yrange [%f:%f]", yMin, yMax),
                 fmt.Sprintf("set offset %f,%f,%f,%f", xOffset, xOffset, yOffset, yOffset),
-                "set parametric",
-                fmt.Sprintf(`plot 0,0 notitle lc rgb "%s" lw 0`, lineColor),
-                "quit")
+                "set parametric")
+    plotCmds = append(append(plotCmds, gnuplotPlotCmd(lineColor, plotData)...), "quit")
     //Send the commands to the gnuplot executable
     execPlot(terminalCmd, &plotCmds)
     //Save the commands if requested
@@ -303,7 +381,7 @@ func Plot(angle float64, terminalCmd, outputCmd, plotTitle, lineColor string, cm
 func MultiPlot(turtleCmds []string, turtleAngles []float64, terminalCmd, outputCmd, plotTitle string, labels []string,
                lineColor string, cmdsFile ...string) {
 /*         Purpose : Plots a set of turtle commands with the given parameters using gnuplot. The result will be
- *                   anisometrically scaled with the subplots generated left to right. The underlying
+ *                   scaled according to MultiPlotScaling with the subplots generated left to right. The underlying
  *                   gnuplot commands can be saved optionally to a text file.
  *       Arguments : turtleCmds   = slice of turtle commands.
  *                   turtleAngles = slice of production angles in degrees.
@@ -315,13 +393,19 @@ func MultiPlot(turtleCmds []string, turtleAngles []float64, terminalCmd, outputC
  *                                  or a 6-digit X11 hex rgb code prefixed with the "#" character.
  *                   cmdsFile     = optional file path for the gnuplot commands.
  *         Returns : None.
- * Externals -  In : _degs2rads, _turtleHistory, _turtleStatus, _validColors
+ * Externals -  In : MultiPlotScaling, WeldVertices, _degs2rads, _turtleHistory, _turtleStatus, _validColors
  * Externals - Out : None.
- *       Functions : calcXoffset, execPlot, fileWrite, halt, makeLogo2Gnuplot, validFgColor
+ *       Functions : calcXoffset, execPlot, fileWrite, geometry2Gnuplot, gnuplotPlotCmd, GridPlot, halt, logo2Geometry,
+ *                   makeLogo2Gnuplot, validFgColor, weldGeometry
  *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
  *                     All other symbols will be ignored.
  *                   - The default turtle heading is 0 degrees.
+ *                   - With Isometric scaling, the subplots are fitted to equal cells of a one-row grid.
  *         History : v1.0.0 - September 28, 2016 - Original release.
+ *                   v1.5.0 - October 18, 2026 - Added the choice of scaling modes.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ *                   v1.24.0 - October 18, 2026 - Interpreted the turtle commands as module arrays.
+ *                                                Replaced HpglPens with the optional pen settings.
  */
     if len(turtleCmds)   == 0 { halt("the turtle commands were not specified") }
     if len(turtleAngles) == 0 { halt("the turtle angles were not stated") }
@@ -334,6 +418,12 @@ func MultiPlot(turtleCmds []string, turtleAngles []float64, terminalCmd, outputC
     }
     if ! validFgColor(lineColor) { halt( fmt.Sprintf("the color name '%s' is not valid. Recognized names are:\n\n%s",
                                                      lineColor, _validColors)) }
+    if MultiPlotScaling < Isometric || MultiPlotScaling > Anisometric { halt("the scaling mode is not valid") }
+    if MultiPlotScaling == Isometric {
+        GridPlot(turtleCmds, turtleAngles, GridLayout{ROWS: 1, SCALING: Isometric}, terminalCmd, outputCmd, plotTitle,
+                 nil, labels, lineColor, cmdsFile...)
+        return
+    }
 
     const( minMargin    = "1"
            maxMargin    = "2"
@@ -346,6 +436,7 @@ func MultiPlot(turtleCmds []string, turtleAngles []float64, terminalCmd, outputC
            logo2Gnuplot = makeLogo2Gnuplot(lineColor)
            drawCmds     []string
            plotCmds     []string
+           plotData     []string
            xMin         float64
            xMax         float64
            yMin         float64
@@ -370,6 +461,7 @@ func MultiPlot(turtleCmds []string, turtleAngles []float64, terminalCmd, outputC
                 fmt.Sprintf(`set style fill solid 1.0 border rgb "%s"`, lineColor),
                 fmt.Sprintf(`set style arrow 1 nohead lc rgb "%s"`, lineColor))
     if plotTitle != "" { plotCmds = append(plotCmds, fmt.Sprintf(`set title "%s" tc rgb "%s"`, plotTitle, lineColor)) }
+    if MultiPlotScaling == SharedIsometric { plotCmds = append(plotCmds, "set size ratio -1") }
    //Convert the turtle commands to headless arrows using unit turtle strides
     xOrigin := 0.
     for k, v := range turtleCmds {
@@ -381,7 +473,19 @@ func MultiPlot(turtleCmds []string, turtleAngles []float64, terminalCmd, outputC
             plotCmds  = append(plotCmds, fmt.Sprintf(`set label "%s" at %f,character 1 center front tc rgb "%s"`,
                                                      labels[k], xOrigin, lineColor))
         }
-        drawCmds, xMin, xMax, yMin, yMax = logo2Gnuplot(&v, xOrigin, turtleAngles[k])
+        if WeldVertices { //draw with welded vertices and merged polylines
+            geometry := logo2Geometry(v, turtleAngles[k], nil)
+            shift    := xOrigin
+            weldGeometry(&geometry)
+            var drawData []string
+            drawCmds, drawData = geometry2Gnuplot(&geometry, func(p _point) _point { return _point{p.X + shift, p.Y} },
+                                                  lineColor)
+            plotData           = append(plotData, drawData...)
+            xMin, xMax         = math.Min(xMin, geometry.XMIN + shift), math.Max(xMax, geometry.XMAX + shift)
+            yMin, yMax         = math.Min(yMin, geometry.YMIN),         math.Max(yMax, geometry.YMAX)
+        } else {
+            drawCmds, xMin, xMax, yMin, yMax = logo2Gnuplot(v, xOrigin, turtleAngles[k])
+        }
         plotCmds = append(plotCmds, drawCmds...)
         if k + 1 < len(turtleCmds) { xOrigin = xMax + <-xOffset }
     }
@@ -389,9 +493,8 @@ func MultiPlot(turtleCmds []string, turtleAngles []float64, terminalCmd, outputC
     plotCmds = append(plotCmds,
                 fmt.Sprintf("set xrange [%f:%f]", xMin, xMax),
                 fmt.Sprintf("set yrange [%f:%f]", yMin, yMax),
-                "set parametric",
-                fmt.Sprintf(`plot 0,0 notitle lc rgb "%s" lw 0`, lineColor),
-                "quit")
+                "set parametric")
+    plotCmds = append(append(plotCmds, gnuplotPlotCmd(lineColor, plotData)...), "quit")
     //Send the commands to the gnuplot executable
     execPlot(terminalCmd, &plotCmds)
     //Save the commands if requested
@@ -408,13 +511,18 @@ func HpglPlot(angle float64, plotTitle string, penWidth float64, hpglPath string
  *                   penWidth  = line-width in millimeters.
  *                   hpglPath  = file path or device port for the HP-GL/2 commands.
  *         Returns : None.
- * Externals -  In : TurtleCmds, _degs2rads, _turtleHistory, _turtleStatus
- * Externals - Out : TurtleCmds
- *       Functions : fileWrite, getHeading, halt, makeLogo2Hpgl
+ * Externals -  In : OptimizePenTravel, TurtleCmds, WeldVertices, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : PenTravel
+ *       Functions : fileWrite, geometry2Hpgl, halt, logo2Geometry, makeLogo2Hpgl, resetPenTravel, strokes2Hpgl,
+ *                   weldGeometry
  *         Remarks : Supported L-system constants are F f + - | $ ( ) [ ]
  *                   All other symbols will be ignored.
  *                   The default turtle heading is 0 degrees.
+ *                   If OptimizePenTravel is set, the strokes are merged and reordered to minimise the pen-up travel.
  *         History : v1.0.0 - September 28, 2016 - Original release.
+ *                   v1.8.0 - October 18, 2026 - Added pen-travel optimisation.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ *                   v1.24.0 - October 18, 2026 - Left TurtleCmds unchanged.
  */
     if TurtleCmds == "" { halt("the turtle commands were not generated") }
     if angle      == 0. { halt("the production angle is zero") }
@@ -432,9 +540,22 @@ func HpglPlot(angle float64, plotTitle string, penWidth float64, hpglPath string
            tmargin   = map[bool]float64{true: 100. - maxMargin, false: 100. - minMargin} [plotTitle != ""]
 
            logo2Hpgl = makeLogo2Hpgl()
+           plotCmds  string
+           xMin      float64
+           xMax      float64
+           yMin      float64
+           yMax      float64
     )
     //Convert the turtle commands to HP-GL/2 commands using unit turtle strides
-    plotCmds, xMin, xMax, yMin, yMax := logo2Hpgl(&TurtleCmds, 0., angle)
+    if report := resetPenTravel(); report != nil || WeldVertices { //draw merged and reordered strokes
+        geometry              := logo2Geometry(TurtleCmds, angle, nil)
+        weldGeometry(&geometry)
+        plotCmds               = strokes2Hpgl(geometry2Hpgl(&geometry, func(p _point) _point { return p }, 6, nil, report),
+                                              false)
+        xMin, xMax, yMin, yMax = geometry.XMIN, geometry.XMAX, geometry.YMIN, geometry.YMAX
+    } else {
+        plotCmds, xMin, xMax, yMin, yMax = logo2Hpgl(TurtleCmds, 0., angle)
+    }
     //Compute offsets so as to center the plot in a square bounding box
     xSpan, ySpan := xMax - xMin, yMax - yMin
     maxSpan      := math.Max(xSpan, ySpan)
@@ -468,24 +589,39 @@ func HpglPlot(angle float64, plotTitle string, penWidth float64, hpglPath string
     return
 } //end func HpglPlot
 func HpglMultiPlot(turtleCmds []string, turtleAngles []float64, plotTitle string, labels []string,
-                   penWidth float64, hpglPath string) {
+                   penWidth float64, hpglPath string, pens ...HpglMedia) {
 /*         Purpose : Converts a set of turtle commands with the given parameters to an HP-GL/2 command set.
- *                   The resulting plot will be anisometrically scaled with the subplots generated left to right in
- *                   landscape mode.
+ *                   The resulting plot will be scaled according to MultiPlotScaling with the subplots generated left to
+ *                   right in landscape mode.
  *       Arguments : turtleCmds   = slice of turtle commands.
  *                   turtleAngles = slice of production angles in degrees.
  *                   plotTitle    = title to be centered at the top of the plot.
  *                   labels       = slice of labels to be centered below each subplot.
- *                   penWidth     = line-width in millimeters.
+ *                   penWidth     = line-width in millimeters, unless the pen widths are set.
  *                   hpglPath     = file path or device port for the HP-GL/2 commands.
+ *                   pens         = optional pen settings, of which only the pens, their assignment and their sorting
+ *                                  are used.
  *         Returns : None.
- * Externals -  In : _degs2rads, _turtleHistory, _turtleStatus
- * Externals - Out : None.
- *       Functions : calcXoffset, fileWrite, getHeading, halt, makeLogo2Hpgl
+ * Externals -  In : MultiPlotScaling, OptimizePenTravel, WeldVertices, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : PenTravel
+ *       Functions : assignStrahler, calcXoffset, fileWrite, geometry2Hpgl, halt, HpglGridPlot, hpglPenWidths,
+ *                   logo2Geometry, makeLogo2Hpgl, makePenSelector, resetPenTravel, strokes2Hpgl, validPenSettings,
+ *                   weldGeometry
  *         Remarks : Supported L-system constants are F f + - | $ ( ) [ ]
  *                   All other symbols will be ignored.
  *                   The default turtle heading is 0 degrees.
+ *                   With Isometric scaling, the subplots are fitted to equal cells of a one-row grid.
+ *                   If several pens are set, they are assigned to the strokes as in HpglPlotWriter; the title and
+ *                   labels are drawn with Pen 1.
+ *                   If OptimizePenTravel is set, the strokes of each subplot are merged and reordered to minimise the
+ *                   pen-up travel.
  *         History : v1.0.0 - September 28, 2016 - Original release.
+ *                   v1.5.0 - October 18, 2026 - Added the choice of scaling modes.
+ *                   v1.7.0 - October 18, 2026 - Added a pen per subplot.
+ *                   v1.8.0 - October 18, 2026 - Added pen-travel optimisation.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ *                   v1.24.0 - October 18, 2026 - Interpreted the turtle commands as module arrays.
+ *                                                Replaced HpglPens with the optional pen settings.
  */
     if len(turtleCmds)   == 0 { halt("the turtle commands were not specified") }
     if len(turtleAngles) == 0 { halt("the turtle angles were not stated") }
@@ -497,6 +633,13 @@ func HpglMultiPlot(turtleCmds []string, turtleAngles []float64, plotTitle string
         halt("fewer labels specified than the number of commands")
     }
     if hpglPath == "" { halt("the path for the plot was not specified") }
+    if MultiPlotScaling < Isometric || MultiPlotScaling > Anisometric { halt("the scaling mode is not valid") }
+    if MultiPlotScaling == Isometric {
+        HpglGridPlot(turtleCmds, turtleAngles, GridLayout{ROWS: 1, SCALING: Isometric}, plotTitle, nil, labels,
+                     penWidth, hpglPath, pens...)
+        return
+    }
+    penSettings := validPenSettings(pens)
 
     const( esc       = 27  //Escape code
            ext       = 3   //End of Text code
@@ -512,6 +655,7 @@ func HpglMultiPlot(turtleCmds []string, turtleAngles []float64, plotTitle string
            drawCmds  string
            logo2Hpgl = makeLogo2Hpgl()
            plotCmds  string
+           strokes   []_hpglStroke
            xMin      float64
            xMax      float64
            yMin      float64
@@ -524,6 +668,7 @@ func HpglMultiPlot(turtleCmds []string, turtleAngles []float64, plotTitle string
     //Initialize
     go calcXoffset(commands, angle, xOffset) //launch coroutine to calc x-offsets of subplots
     //Convert the turtle commands to HP-GL/2 commands using unit turtle strides
+    report  := resetPenTravel()
     xOrigin := 0.
     for k, v := range turtleCmds {
         if k + 1 < len(turtleCmds) {
@@ -531,10 +676,22 @@ func HpglMultiPlot(turtleCmds []string, turtleAngles []float64, plotTitle string
             angle<-    turtleAngles[k+1]
         }
         if labels[k] != "" { plotCmds += fmt.Sprintf("PU%f,%f;LO16;LB%s%c;\n", xOrigin, -yNudge, labels[k], ext) }
-        drawCmds, xMin, xMax, yMin, yMax = logo2Hpgl(&v, xOrigin, turtleAngles[k])
-        plotCmds += drawCmds
+        if report != nil || WeldVertices || len(penSettings.PENS) > 1 { //draw merged, reordered or multi-pen strokes
+            geometry := logo2Geometry(v, turtleAngles[k], nil)
+            shift    := xOrigin
+            if penSettings.PEN_BY == PenByStrahler { assignStrahler(&geometry) }
+            weldGeometry(&geometry)
+            strokes   = append(strokes, geometry2Hpgl(&geometry, func(p _point) _point { return _point{p.X + shift, p.Y} },
+                                                      6, makePenSelector(penSettings, k), report)...)
+            xMin, xMax = math.Min(xMin, geometry.XMIN + shift), math.Max(xMax, geometry.XMAX + shift)
+            yMin, yMax = math.Min(yMin, geometry.YMIN),         math.Max(yMax, geometry.YMAX)
+        } else {
+            drawCmds, xMin, xMax, yMin, yMax = logo2Hpgl(v, xOrigin, turtleAngles[k])
+            plotCmds += drawCmds
+        }
         if k + 1 < len(turtleCmds) { xOrigin = xMax + <-xOffset }
     }
+    plotCmds += strokes2Hpgl(strokes, penSettings.PEN_SORT)
     //Compose the remaining HP-GL/2 commands
     plotCmds = //HP RTL: enter HP-GL/2 mode, begin a plot and initialize HP-GL/2
                fmt.Sprintf("%c%%-1BBPIN;\n", esc) +
@@ -542,14 +699,16 @@ func HpglMultiPlot(turtleCmds []string, turtleAngles []float64, plotTitle string
                "RO90;\n" +
                //set the margins for the drawing
                fmt.Sprintf("IR%f,%f,%f,%f;\n", rmargin, bmargin, lmargin, tmargin) +
-               //set the scaling as anisotropic
-               fmt.Sprintf("SC%f,%f,%f,%f,0;\n", xMin, xMax, yMin, yMax) +
-               //select Pen 1 (black) and set its width in millimeters
-               fmt.Sprintf("SP1;WU0;PW%f;\n", penWidth) +
+               //set the scaling as isotropic for a shared scale, anisotropic otherwise
+               fmt.Sprintf("SC%f,%f,%f,%f,%d;\n", xMin, xMax, yMin, yMax,
+                           map[bool]int{true: 1, false: 0} [MultiPlotScaling == SharedIsometric]) +
+               //select Pen 1 (black) and set the pen widths in millimeters
+               hpglPenWidths(penWidth, penSettings.PENS) +
                //add the previous turtle pen commands
                plotCmds + "\n"
     if plotTitle != "" { //draw the plot title centered at the top
-        plotCmds += fmt.Sprintf("PU%f,%f;LO14;LB%s%c;\n", 0.5*(xMin + xMax), yMax + yNudge, plotTitle, ext)
+        plotCmds += map[bool]string{true: "SP1;\n", false: ""} [len(penSettings.PENS) > 1] +
+                    fmt.Sprintf("PU%f,%f;LO14;LB%s%c;\n", 0.5*(xMin + xMax), yMax + yNudge, plotTitle, ext)
     }
     //end (page advance)
     plotCmds += "PG;"
@@ -564,6 +723,8 @@ type _turtleStatus struct {
     HEADING float64 //turtle's heading in degrees
     X       float64 //turtle's x ordinate
     Y       float64 //turtle's y ordinate
+    STEPS   int     //turtle's heading in lattice steps, for the exact turtle
+    COORDS  []int64 //turtle's position in lattice coordinates, for the exact turtle
 }
 type _turtleHistory []_turtleStatus
 const _progressBarLen = 50
@@ -599,6 +760,7 @@ func halt(msg string) {
 func updateProgressBar(title string, current, total int) {
     //code derived from Graham King's post "Pretty command line / console output on Unix in Python and Go Lang"
     //(http://www.darkcoding.net/software/pretty-command-line-console-output-on-unix-in-python-and-go-lang/)
+    if !ProgressBars || total < 1 { return } //disabled or nothing to report for single-symbol commands
     prefix := fmt.Sprintf("%s: %d / %d ", title, current, total)
     amount := int(0.1 + float32(_progressBarLen) * float32(current) / float32(total))
     remain := _progressBarLen - amount
@@ -626,13 +788,14 @@ func calcXoffset(commands <-chan string, productionAngle <-chan float64, xOffset
          xNudge = 2.
     )
     for turtleCmds := range commands {
-        turtleCmds  = strings.NewReplacer("+-", "", "-+", "").Replace(turtleCmds) //remove pointless turns
-        angle      := <-productionAngle
+        modules, err := parseModules(turtleCmds)
+        if err != nil { halt(err.Error()) }
+        angle := <-productionAngle
         turtle.HEADING, turtle.X = 0., 0.
-        xMin := 0.
-        pos  := 0
-        for pos < len(turtleCmds) {
-            symbol := string(turtleCmds[pos])
+        xMin  := 0.
+        param := 0
+        for _, id := range modules.SYMBOLS {
+            symbol := string(id)
             switch symbol {
                 case "F", "f": //draw or move forward
                     switch math.Mod(turtle.HEADING, 360.) {
@@ -656,17 +819,13 @@ func calcXoffset(commands <-chan string, productionAngle <-chan float64, xOffset
                 case "$": //head due north
                     turtle.HEADING = 90.
                 case "(": //set arbitrary heading
-                    turtle.HEADING, pos = getHeading(&turtleCmds, pos)
+                    turtle.HEADING, param = modules.SLAB[param], param + 1
                 case "[": //store status
                     stack.push(turtle)
                 case "]": //restore status
                     turtle = stack.pop()
                 case "{", "}": //ignore polygon mode
-                default:  //remove all instances of a production variable
-                    turtleCmds = strings.NewReplacer(symbol, "").Replace(turtleCmds)
-                    pos--
             }
-            pos++
         }
         xOffset<- math.Abs(xMin) + xNudge
     }
@@ -696,66 +855,73 @@ func fileWrite(filepath string, content string) {
     if err = writer.Close(); err != nil { halt("writer.Close - " + err.Error()) }
     return
 } //end func fileWrite
-func getHeading(cmds *string, posLP int) (heading float64, pos int) {
-    matches := _reHeading.FindStringSubmatch((*cmds)[posLP:])
-    if matches == nil { halt("the specified angle is not syntactically well-formed") }
-    heading, err := strconv.ParseFloat(matches[1], 64)
-    if err != nil { halt("the specified angle is not syntactically well-formed") }
-    pos = posLP + len(matches[0]) - 1
-    return
-} //end func getHeading
-func makeLogo2Gnuplot(lineColor string) func(turtleCmds *string, xOrigin, angle float64) (plotCmds []string,
-                                                                                          xMini, xMaxi, yMini, yMaxi float64) {
+func makeLogo2Gnuplot(lineColor string) func(turtleCmds string, xOrigin, angle float64) (plotCmds []string,
+                                                                                         xMini, xMaxi, yMini, yMaxi float64) {
     xMin, xMax := 0., 0.
     yMin, yMax := 0., 0.
-    return func(turtleCmds *string, xOrigin, angle float64) (plotCmds []string, xMini, xMaxi, yMini, yMaxi float64) {
+    return func(turtleCmds string, xOrigin, angle float64) (plotCmds []string, xMini, xMaxi, yMini, yMaxi float64) {
             var( convert2Gnuplot = makeConvert2Gnuplot(lineColor)
+                 param           int //next parameter in the slab
                  stack           _turtleHistory
-                 turtle          = _turtleStatus{0., xOrigin, 0.}
+                 turtle          = _turtleStatus{HEADING: 0., X: xOrigin, Y: 0.}
             )
             //Initialize
-            *turtleCmds = strings.NewReplacer("+-", "", "-+", "").Replace(*turtleCmds) //remove pointless turns
-            //Convert the turtle commands to gnuplot line segments using unit turtle strides
-            pos := 0
-            for pos < len(*turtleCmds) {
-                updateProgressBar("logo -> gnuplot", pos, len(*turtleCmds)-1)
-                symbol := string((*turtleCmds)[pos])
+            modules, err := parseModules(turtleCmds)
+            if err != nil { halt(err.Error()) }
+            lattice := makeLattice(&modules, angle, ExactTurtle)
+            //Convert the modules to gnuplot line segments using unit turtle strides
+            for k, id := range modules.SYMBOLS {
+                updateProgressBar("logo -> gnuplot", k, len(modules.SYMBOLS)-1)
+                symbol := string(id)
                 switch symbol {
                     case "F", "f": //draw or move forward
                         xFrom, yFrom := turtle.X, turtle.Y
-                        switch math.Mod(turtle.HEADING, 360.) {
-                            case 0.:
-                                turtle.X++
-                                xMax  = math.Max(xMax, turtle.X)
-                            case 90., -270.:
-                                turtle.Y++
-                                yMax  = math.Max(yMax, turtle.Y)
-                            case 180., -180.:
-                                turtle.X--
-                                xMin  = math.Min(xMin, turtle.X)
-                            case 270., -90.:
-                                turtle.Y--
-                                yMin  = math.Min(yMin, turtle.Y)
-                            default:
-                                radians    := turtle.HEADING * _degs2rads
-                                turtle.X   += math.Cos(radians)
-                                turtle.Y   += math.Sin(radians)
-                                xMin, xMax  = math.Min(xMin, turtle.X), math.Max(xMax, turtle.X)
-                                yMin, yMax  = math.Min(yMin, turtle.Y), math.Max(yMax, turtle.Y)
+                        if lattice != nil { //exact turtle
+                            turtle.COORDS       = lattice.forward(turtle.COORDS, turtle.STEPS)
+                            turtle.X, turtle.Y  = lattice.point(turtle.COORDS)
+                            turtle.X           += xOrigin
+                            xMin, xMax          = math.Min(xMin, turtle.X), math.Max(xMax, turtle.X)
+                            yMin, yMax          = math.Min(yMin, turtle.Y), math.Max(yMax, turtle.Y)
+                        } else {
+                            switch math.Mod(turtle.HEADING, 360.) {
+                                case 0.:
+                                    turtle.X++
+                                    xMax  = math.Max(xMax, turtle.X)
+                                case 90., -270.:
+                                    turtle.Y++
+                                    yMax  = math.Max(yMax, turtle.Y)
+                                case 180., -180.:
+                                    turtle.X--
+                                    xMin  = math.Min(xMin, turtle.X)
+                                case 270., -90.:
+                                    turtle.Y--
+                                    yMin  = math.Min(yMin, turtle.Y)
+                                default:
+                                    radians    := turtle.HEADING * _degs2rads
+                                    turtle.X   += math.Cos(radians)
+                                    turtle.Y   += math.Sin(radians)
+                                    xMin, xMax  = math.Min(xMin, turtle.X), math.Max(xMax, turtle.X)
+                                    yMin, yMax  = math.Min(yMin, turtle.Y), math.Max(yMax, turtle.Y)
+                            }
                         }
                         if cmd := convert2Gnuplot(symbol, xFrom, yFrom, turtle.X, turtle.Y); cmd != "" {
                             plotCmds = append(plotCmds, cmd)
                         }
                     case "+": //turn left
                         turtle.HEADING += angle
+                        if lattice != nil { turtle.STEPS = lattice.turn(turtle.STEPS, lattice.TURN) }
                     case "-": //turn right
                         turtle.HEADING -= angle
+                        if lattice != nil { turtle.STEPS = lattice.turn(turtle.STEPS, -lattice.TURN) }
                     case "|": //turn away
                         turtle.HEADING += 180.
+                        if lattice != nil { turtle.STEPS = lattice.turn(turtle.STEPS, lattice.HEADINGS / 2) }
                     case "$": //head due north
                         turtle.HEADING = 90.
+                        if lattice != nil { turtle.STEPS = lattice.heading(90.) }
                     case "(": //set arbitrary heading
-                        turtle.HEADING, pos = getHeading(turtleCmds, pos)
+                        turtle.HEADING, param = modules.SLAB[param], param + 1
+                        if lattice != nil { turtle.STEPS = lattice.heading(turtle.HEADING) }
                     case "[": //store status
                         stack.push(turtle)
                     case "]": //restore status
@@ -764,11 +930,7 @@ func makeLogo2Gnuplot(lineColor string) func(turtleCmds *string, xOrigin, angle
                         convert2Gnuplot(symbol, turtle.X, turtle.Y)
                     case "}": //end  polygon mode
                         plotCmds = append(plotCmds, convert2Gnuplot(symbol))
-                    default:  //remove all instances of a production variable
-                        *turtleCmds = strings.NewReplacer(symbol, "").Replace(*turtleCmds)
-                        pos--
                 }
-                pos++
             }
             xMini, xMaxi, yMini, yMaxi = xMin, xMax, yMin, yMax
             return
@@ -791,55 +953,70 @@ func makeConvert2Gnuplot(lineColor string) func(symbol string, coords ...float64
             return
            }
 } //end func makeConvert2Gnuplot
-func makeLogo2Hpgl() func(turtleCmds *string, xOrigin, angle float64) (plotCmds string, xMini, xMaxi, yMini, yMaxi float64) {
+func makeLogo2Hpgl() func(turtleCmds string, xOrigin, angle float64) (plotCmds string, xMini, xMaxi, yMini, yMaxi float64) {
     xMin, xMax := 0., 0.
     yMin, yMax := 0., 0.
-    return func(turtleCmds *string, xOrigin, angle float64) (plotCmds string, xMini, xMaxi, yMini, yMaxi float64) {
+    return func(turtleCmds string, xOrigin, angle float64) (plotCmds string, xMini, xMaxi, yMini, yMaxi float64) {
             var( convert2Hpgl = makeConvert2Hpgl()
+                 param        int //next parameter in the slab
                  stack        _turtleHistory
-                 turtle       = _turtleStatus{0., xOrigin, 0.}
+                 turtle       = _turtleStatus{HEADING: 0., X: xOrigin, Y: 0.}
             )
             //Initialize
-            *turtleCmds = strings.NewReplacer("+-", "", "-+", "").Replace(*turtleCmds) //remove pointless turns
-            plotCmds    = convert2Hpgl("f", xOrigin, 0.)
-            //Convert the turtle commands to HP-GL/2 commands using unit turtle strides
-            pos := 0
-            for pos < len(*turtleCmds) {
-                updateProgressBar("logo -> HP-GL/2", pos, len(*turtleCmds)-1)
-                symbol := string((*turtleCmds)[pos])
+            modules, err := parseModules(turtleCmds)
+            if err != nil { halt(err.Error()) }
+            lattice := makeLattice(&modules, angle, ExactTurtle)
+            plotCmds = convert2Hpgl("f", xOrigin, 0.)
+            //Convert the modules to HP-GL/2 commands using unit turtle strides
+            for k, id := range modules.SYMBOLS {
+                updateProgressBar("logo -> HP-GL/2", k, len(modules.SYMBOLS)-1)
+                symbol := string(id)
                 switch symbol {
                     case "F", "f": //draw or move forward
-                        switch math.Mod(turtle.HEADING, 360.) {
-                            case 0.:
-                                turtle.X++
-                                xMax = math.Max(xMax, turtle.X)
-                            case 90., -270.:
-                                turtle.Y++
-                                yMax = math.Max(yMax, turtle.Y)
-                            case 180., -180.:
-                                turtle.X--
-                                xMin = math.Min(xMin, turtle.X)
-                            case 270., -90.:
-                                turtle.Y--
-                                yMin = math.Min(yMin, turtle.Y)
-                            default:
-                                radians    := turtle.HEADING * _degs2rads
-                                turtle.X   += math.Cos(radians)
-                                turtle.Y   += math.Sin(radians)
-                                xMin, xMax  = math.Min(xMin, turtle.X), math.Max(xMax, turtle.X)
-                                yMin, yMax  = math.Min(yMin, turtle.Y), math.Max(yMax, turtle.Y)
+                        if lattice != nil { //exact turtle
+                            turtle.COORDS       = lattice.forward(turtle.COORDS, turtle.STEPS)
+                            turtle.X, turtle.Y  = lattice.point(turtle.COORDS)
+                            turtle.X           += xOrigin
+                            xMin, xMax          = math.Min(xMin, turtle.X), math.Max(xMax, turtle.X)
+                            yMin, yMax          = math.Min(yMin, turtle.Y), math.Max(yMax, turtle.Y)
+                        } else {
+                            switch math.Mod(turtle.HEADING, 360.) {
+                                case 0.:
+                                    turtle.X++
+                                    xMax = math.Max(xMax, turtle.X)
+                                case 90., -270.:
+                                    turtle.Y++
+                                    yMax = math.Max(yMax, turtle.Y)
+                                case 180., -180.:
+                                    turtle.X--
+                                    xMin = math.Min(xMin, turtle.X)
+                                case 270., -90.:
+                                    turtle.Y--
+                                    yMin = math.Min(yMin, turtle.Y)
+                                default:
+                                    radians    := turtle.HEADING * _degs2rads
+                                    turtle.X   += math.Cos(radians)
+                                    turtle.Y   += math.Sin(radians)
+                                    xMin, xMax  = math.Min(xMin, turtle.X), math.Max(xMax, turtle.X)
+                                    yMin, yMax  = math.Min(yMin, turtle.Y), math.Max(yMax, turtle.Y)
+                            }
                         }
                         plotCmds += convert2Hpgl(symbol, turtle.X, turtle.Y)
                     case "+": //turn left
                         turtle.HEADING += angle
+                        if lattice != nil { turtle.STEPS = lattice.turn(turtle.STEPS, lattice.TURN) }
                     case "-": //turn right
                         turtle.HEADING -= angle
+                        if lattice != nil { turtle.STEPS = lattice.turn(turtle.STEPS, -lattice.TURN) }
                     case "|": //turn away
                         turtle.HEADING += 180.
+                        if lattice != nil { turtle.STEPS = lattice.turn(turtle.STEPS, lattice.HEADINGS / 2) }
                     case "$": //head due north
                         turtle.HEADING = 90.
+                        if lattice != nil { turtle.STEPS = lattice.heading(90.) }
                     case "(": //set arbitrary heading
-                        turtle.HEADING, pos = getHeading(turtleCmds, pos)
+                        turtle.HEADING, param = modules.SLAB[param], param + 1
+                        if lattice != nil { turtle.STEPS = lattice.heading(turtle.HEADING) }
                     case "[": //store status
                         stack.push(turtle)
                     case "]": //restore status
@@ -847,11 +1024,7 @@ func makeLogo2Hpgl() func(turtleCmds *string, xOrigin, angle float64) (plotCmds
                         plotCmds += convert2Hpgl("f", turtle.X, turtle.Y)
                     case "{", "}": //start or end polygon mode
                         plotCmds += convert2Hpgl(symbol, turtle.X, turtle.Y)
-                    default:  //remove all instances of a production variable
-                        *turtleCmds = strings.NewReplacer(symbol, "").Replace(*turtleCmds)
-                        pos--
                 }
-                pos++
             }
             plotCmds += ";"
             xMini, xMaxi, yMini, yMaxi = xMin, xMax, yMin, yMax
@@ -877,6 +1050,10 @@ func makeConvert2Hpgl() func(symbol string, x, y float64) (pltCmd string) {
             return
            }
 } //end func makeConvert2Hpgl
+func streamWrite(writer io.Writer, content string) {
+    if _, err := io.WriteString(writer, content); err != nil { halt("io.WriteString - " + err.Error()) }
+    return
+} //end func streamWrite
 func validFgColor(fgColor string) bool {
     if fgColor == "" {
         return false
@@ -887,65 +1064,5 @@ func validFgColor(fgColor string) bool {
     _, ok := _colorNames[fgColor]
     return ok
 } //end func validFgColor
-////Context searches
-func getContextLHS(io chan string) {
-    for lhs := range io {
-        context := ""
-        pos     := len(lhs) - 1
-        searchLoop: for pos >= 0 {
-            symbol := string(lhs[pos])
-            switch symbol {
-                case "F", "+", "-", "$": //ignore constant
-                case "[":                //keep looking for parent's symbol
-                case "]":                //skip over branch symbol(s)
-                    unmatchedBrakets := 1
-                    for unmatchedBrakets != 0 {
-                        pos--
-                        char := string(lhs[pos])
-                        if char == "[" { unmatchedBrakets-- }
-                        if char == "]" { unmatchedBrakets++ }
-                    }
-                case "0", "1":
-                    context = symbol
-                    break searchLoop
-                default:
-                    halt(fmt.Sprintf("the symbol '%s' is not supported", symbol))
-            }
-            pos--
-        }
-        io<- context
-    }
-    return
-} //end coroutine getContextLHS
-func getContextRHS(io chan string) {
-    for rhs := range io {
-        context := ""
-        pos     := 0
-        searchLoop: for pos < len(rhs) {
-            symbol := string(rhs[pos])
-            switch symbol {
-                case "F", "+", "-", "$": //ignore constant
-                case "]":                //reached top of branch
-                    break searchLoop
-                case "[":                //skip over side branch symbol(s)
-                    unmatchedBrakets := 1
-                    for unmatchedBrakets != 0 {
-                        pos++
-                        char := string(rhs[pos])
-                        if char == "[" { unmatchedBrakets++ }
-                        if char == "]" { unmatchedBrakets-- }
-                    }
-                case "0", "1":
-                    context = symbol
-                    break searchLoop
-                default:
-                    halt(fmt.Sprintf("the symbol '%s' is not supported", symbol))
-            }
-            pos++
-        }
-        io<- context
-    }
-    return
-} //end coroutine getContextRHS
 //===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
 //end of Package lsystems
@@ -0,0 +1,153 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      compact module arrays for the derivation and interpretation of turtle commands.
+ *  Remarks:
+ *      A module array holds the symbol of each module, one byte apiece, and a slab of the parameters of all the modules
+ *      in module order, each heading declaration being a single "(" module whose parameter is the heading in degrees.
+ *      The rewriting engines, DeterministicPairs, Derive, Stochastic and HogewegHesper, derive literal module arrays,
+ *      which keep every symbol, from step to step, and only format them as turtle commands for TurtleCmds and the
+ *      error-returning functions. The heading declarations are then written in their shortest form, e.g., "(30.0)"
+ *      becomes "(30)". Deterministic is the exception, since its strings.Replacer can only rewrite strings.
+ *      The interpreters keep only the turtle constants F f + - | $ ( [ ] { } ; , : the production variables are
+ *      dropped as the commands are appended and pointless turns, i.e., "+-" and "-+", cancel out. They thus walk the
+ *      turtle commands once, without ever copying or rescanning them, and the timed derivation appends its modules
+ *      directly.
+ *  History: v1.24.0 - October 18, 2026 - Original release.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "errors"
+    "math"
+    "strconv"
+    "strings"
+)
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+const _turtleConstants = "Ff+-|$([]{};," //symbols kept in module arrays
+type _modules struct {
+    SYMBOLS []byte    //symbol of each module, "(" standing for a whole heading declaration
+    SLAB    []float64 //parameters of the modules in module order, i.e., the heading of each "(" module
+    TURN    bool      //true if the last symbol appended is a turn that an opposite turn would cancel
+}
+func parseModules(turtleCmds string) (modules _modules, err error) {
+    modules.SYMBOLS = make([]byte, 0, len(turtleCmds))
+    err             = modules.appendCmds(turtleCmds)
+    return
+} //end func parseModules
+func(modules *_modules) appendCmds(turtleCmds string) error {
+/*         Purpose : Appends turtle commands to a module array.
+ *       Arguments : turtleCmds = turtle commands.
+ *         Returns : nil, or an error if a heading declaration is not well-formed.
+ * Externals -  In : _reHeading, _turtleConstants
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : - Pointless turns cancel out as they would in a single left-to-right pass over the concatenated
+ *                     commands, so that appending commands piecemeal yields the same modules as appending them at once.
+ *                   - A production variable between two opposite turns keeps them from cancelling out.
+ *         History : v1.24.0 - October 18, 2026 - Original release.
+ */
+    for pos := 0; pos < len(turtleCmds); pos++ {
+        symbol := turtleCmds[pos]
+        switch {
+            case (symbol == '+' || symbol == '-') && modules.TURN &&
+                 symbol != modules.SYMBOLS[len(modules.SYMBOLS)-1]: //cancel a pointless turn
+                modules.SYMBOLS, modules.TURN = modules.SYMBOLS[:len(modules.SYMBOLS)-1], false
+                continue
+            case symbol == '(': //heading declaration
+                matches := _reHeading.FindStringSubmatch(turtleCmds[pos:])
+                if matches == nil { return errors.New("the specified angle is not syntactically well-formed") }
+                heading, err := strconv.ParseFloat(matches[1], 64)
+                if err != nil { return errors.New("the specified angle is not syntactically well-formed") }
+                modules.SLAB = append(modules.SLAB, heading)
+                pos         += len(matches[0]) - 1
+            case strings.IndexByte(_turtleConstants, symbol) < 0: //drop a production variable
+                modules.TURN = false
+                continue
+        }
+        modules.SYMBOLS = append(modules.SYMBOLS, symbol)
+        modules.TURN    = symbol == '+' || symbol == '-'
+    }
+    return nil
+} //end func appendCmds
+func literalModules(turtleCmds string) (modules _modules) {
+/*         Purpose : Parses turtle commands into a module array keeping every symbol, for rewriting.
+ *       Arguments : turtleCmds = turtle commands.
+ *         Returns : the module array.
+ * Externals -  In : _reHeading
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : A "(" that does not start a well-formed heading declaration is kept as a bare "(" module whose
+ *                   parameter is NaN, the rest of the declaration following as ordinary symbols, so that the turtle
+ *                   commands are formatted back as they were.
+ *         History : v1.24.0 - October 18, 2026 - Original release.
+ */
+    modules.SYMBOLS = make([]byte, 0, len(turtleCmds))
+    for pos := 0; pos < len(turtleCmds); pos++ {
+        symbol := turtleCmds[pos]
+        if symbol == '(' {
+            heading := math.NaN()
+            if matches := _reHeading.FindStringSubmatch(turtleCmds[pos:]); matches != nil {
+                if value, err := strconv.ParseFloat(matches[1], 64); err == nil {
+                    heading, pos = value, pos + len(matches[0]) - 1
+                }
+            }
+            modules.SLAB = append(modules.SLAB, heading)
+        }
+        modules.SYMBOLS = append(modules.SYMBOLS, symbol)
+    }
+    return
+} //end func literalModules
+func(modules *_modules) turtleCmds() string {
+/*         Purpose : Formats a literal module array as turtle commands.
+ *       Arguments : None.
+ *         Returns : the turtle commands.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : The headings are written in their shortest decimal form, which _reHeading accepts.
+ *         History : v1.24.0 - October 18, 2026 - Original release.
+ */
+    if len(modules.SLAB) == 0 { return string(modules.SYMBOLS) }
+    var( turtleCmds strings.Builder
+         slab       = 0
+         lo         = 0
+    )
+    turtleCmds.Grow(len(modules.SYMBOLS) + 8 * len(modules.SLAB))
+    for pos, symbol := range modules.SYMBOLS {
+        if symbol != '(' { continue }
+        turtleCmds.Write(modules.SYMBOLS[lo:pos+1])
+        if heading := modules.SLAB[slab]; !math.IsNaN(heading) {
+            turtleCmds.WriteString(strconv.FormatFloat(heading, 'f', -1, 64))
+            turtleCmds.WriteByte(')')
+        }
+        slab++
+        lo = pos + 1
+    }
+    turtleCmds.Write(modules.SYMBOLS[lo:])
+    return turtleCmds.String()
+} //end func turtleCmds
+func(modules *_modules) matchAt(pos, slab int, predecessor *_modules) bool {
+/*         Purpose : Reports whether a literal module array holds a predecessor at a position.
+ *       Arguments : pos         = module position.
+ *                   slab        = slab position of the first parameter at or after the module position.
+ *                   predecessor = literal module array of the predecessor.
+ *         Returns : true if the modules match, their headings included.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : A bare "(" only matches a bare "(".
+ *         History : v1.24.0 - October 18, 2026 - Original release.
+ */
+    symbols := predecessor.SYMBOLS
+    if len(modules.SYMBOLS) - pos < len(symbols) || string(modules.SYMBOLS[pos:pos+len(symbols)]) != string(symbols) {
+        return false
+    }
+    for k, heading := range predecessor.SLAB {
+        if value := modules.SLAB[slab+k]; value != heading && !(math.IsNaN(value) && math.IsNaN(heading)) { return false }
+    }
+    return true
+} //end func matchAt
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of modules.go
@@ -0,0 +1,473 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      parallel rewriting engine sharing each derivation step among goroutines.
+ *  Variables:
+ *      DerivationSeed int64
+ *          Seed of the stochastic choices of Stochastic, or 0 to draw one from math/rand on each call (default: 0).
+ *      DerivationWorkers int
+ *          Number of goroutines sharing each derivation step of DeterministicPairs, Derive, Stochastic and
+ *          HogewegHesper, or 0 for one per CPU (default: 0).
+ *  Remarks:
+ *      Each derivation step splits the string into chunks of about 64K symbols, rewritten concurrently and then joined
+ *      in order. The cuts depend on the string alone, never on the number of workers, and each chunk draws its
+ *      stochastic choices from its own random stream, seeded from DerivationSeed, the derivation step, the string
+ *      length and the chunk number, so that a given seed yields the very same derivation with any number of workers.
+ *      The cuts of DeterministicPairs and Derive avoid splitting any predecessor. The context searches of
+ *      HogewegHesper stay within each chunk: the bracket contexts at the cuts are chained from a first pass over the
+ *      chunks, so that every chunk sees the same contexts as a serial derivation. Deterministic's strings.Replacer does
+ *      not reveal its predecessors to the cuts, so that DeterministicPairs, given the same rules as pairs, is its
+ *      parallel counterpart.
+ *  History: v1.23.0 - October 18, 2026 - Original release.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "bytes"
+    "errors"
+    "fmt"
+    "math"
+    "math/rand"
+    "runtime"
+    "strings"
+    "sync"
+    "sync/atomic"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+var( DerivationSeed    int64 //seed of the stochastic choices, or 0 to draw one from math/rand on each call
+     DerivationWorkers int   //goroutines sharing each derivation step, or 0 for one per CPU
+)
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+const( _derivationChunk = 1 << 16 //symbols per chunk of a derivation step
+       _pendingModules  = 1 << 12 //modules a chunk writes before counting them against the limit
+       _noContext       = 0       //Hogeweg and Hesper context of a symbol without a 0 or 1 beside it
+)
+type _chunkRewriter func(chunk, lo, hi, slab int, writer *_moduleWriter) error
+type _derivation struct { //literal module arrays of a derivation, rewritten back and forth
+    SOURCE  _modules        //modules of the current order
+    TARGET  _modules        //modules of the next order, joined from the chunks
+    CHUNKS  []_moduleWriter //rewritten modules of each chunk, reused from step to step
+    LIMIT   int             //most modules allowed in the next order
+    TOTAL   int64           //modules written by all the chunks so far
+}
+type _moduleWriter struct { //module array of a chunk refusing to grow beyond the limit of its derivation
+    MODULES    _modules
+    DERIVATION *_derivation
+    PENDING    int //modules written but not yet counted in the derivation's total
+}
+func chunkCuts(length int, safe func(pos int) bool) (cuts []int) {
+/*         Purpose : Splits a module array into chunks to be rewritten concurrently.
+ *       Arguments : length = number of modules to be rewritten.
+ *                   safe   = function reporting whether the modules may be cut before a position, or nil if they may
+ *                            be cut anywhere.
+ *         Returns : the chunk boundaries, from 0 to the number of modules.
+ * Externals -  In : _derivationChunk
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : Each cut is the first safe position at or after a multiple of the chunk size past the previous cut.
+ *         History : v1.23.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Cut module arrays.
+ */
+    cuts = append(cuts, 0)
+    for pos := _derivationChunk; pos < length; pos += _derivationChunk {
+        for pos < length && safe != nil && !safe(pos) { pos++ }
+        if pos < length { cuts = append(cuts, pos) }
+    }
+    return append(cuts, length)
+} //end func chunkCuts
+func forChunks(chunks int, work func(chunk int) error) error {
+/*         Purpose : Runs a function on every chunk concurrently.
+ *       Arguments : chunks = number of chunks.
+ *                   work   = function processing a chunk.
+ *         Returns : nil, or the error of the first failing chunk in chunk order.
+ * Externals -  In : DerivationWorkers
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : A single chunk, or a single worker, is processed on the calling goroutine.
+ *         History : v1.23.0 - October 18, 2026 - Original release.
+ */
+    workers := DerivationWorkers
+    if workers <= 0 { workers = runtime.NumCPU() }
+    if workers > chunks { workers = chunks }
+    if workers <= 1 {
+        for k := 0; k < chunks; k++ {
+            if err := work(k); err != nil { return err }
+        }
+        return nil
+    }
+    var( errs = make([]error, chunks)
+         next = make(chan int)
+         wait sync.WaitGroup
+    )
+    for w := 0; w < workers; w++ {
+        wait.Add(1)
+        go func() {
+            defer wait.Done()
+            for k := range next {
+                errs[k] = work(k)
+            }
+        }()
+    }
+    for k := 0; k < chunks; k++ {
+        next<- k
+    }
+    close(next)
+    wait.Wait()
+    for _, err := range errs {
+        if err != nil { return err }
+    }
+    return nil
+} //end func forChunks
+func newDerivation(axiom string, limit int) *_derivation {
+    if limit <= 0 { limit = math.MaxInt }
+    return &_derivation{SOURCE: literalModules(axiom), LIMIT: limit}
+} //end func newDerivation
+func(derivation *_derivation) step(cuts []int, rewrite _chunkRewriter) error {
+/*         Purpose : Rewrites the chunks of the current order concurrently and joins them into the next order.
+ *       Arguments : cuts    = chunk boundaries, from 0 to the number of modules.
+ *                   rewrite = function writing the rewritten modules of a chunk, given the slab position of its first
+ *                             parameter.
+ *         Returns : nil, or the error of the first failing chunk.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : count, forChunks
+ *         Remarks : - The chunks share the limit, so that runaway growth stops all of them. Each chunk counts its
+ *                     modules against the limit in batches of _pendingModules.
+ *                   - The source and target arrays swap roles from step to step, and the chunk arrays are reused, so
+ *                     that a derivation allocates little beyond the growth of its modules.
+ *         History : v1.23.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Rewrote module arrays.
+ */
+    var( chunks = len(cuts) - 1
+         slabs  = make([]int, chunks + 1) //slab position of the first parameter of each chunk
+    )
+    //Locate the parameters of the chunks
+    if len(derivation.SOURCE.SLAB) > 0 {
+        forChunks(chunks, func(k int) error {
+            slabs[k+1] = bytes.Count(derivation.SOURCE.SYMBOLS[cuts[k]:cuts[k+1]], []byte{'('})
+            return nil
+        })
+        for k := 1; k <= chunks; k++ {
+            slabs[k] += slabs[k-1]
+        }
+    }
+    //Rewrite the chunks
+    for len(derivation.CHUNKS) < chunks {
+        derivation.CHUNKS = append(derivation.CHUNKS, _moduleWriter{DERIVATION: derivation})
+    }
+    for k := range derivation.CHUNKS {
+        derivation.CHUNKS[k].MODULES.SYMBOLS = derivation.CHUNKS[k].MODULES.SYMBOLS[:0]
+        derivation.CHUNKS[k].MODULES.SLAB    = derivation.CHUNKS[k].MODULES.SLAB[:0]
+        derivation.CHUNKS[k].PENDING         = 0
+    }
+    derivation.TOTAL = 0
+    err := forChunks(chunks, func(k int) error {
+        if err := rewrite(k, cuts[k], cuts[k+1], slabs[k], &derivation.CHUNKS[k]); err != nil { return err }
+        return derivation.CHUNKS[k].count()
+    })
+    if err != nil { return err }
+    //Join the chunks
+    if chunks == 1 { //take the chunk's arrays, leaving it the current ones to reuse
+        derivation.SOURCE, derivation.CHUNKS[0].MODULES = derivation.CHUNKS[0].MODULES, derivation.SOURCE
+        return nil
+    }
+    target := &derivation.TARGET
+    target.SYMBOLS, target.SLAB = target.SYMBOLS[:0], target.SLAB[:0]
+    for k := 0; k < chunks; k++ {
+        target.SYMBOLS = append(target.SYMBOLS, derivation.CHUNKS[k].MODULES.SYMBOLS...)
+        target.SLAB    = append(target.SLAB, derivation.CHUNKS[k].MODULES.SLAB...)
+    }
+    derivation.SOURCE, derivation.TARGET = derivation.TARGET, derivation.SOURCE
+    return nil
+} //end func step
+func(writer *_moduleWriter) write(modules *_modules) error { //appends a whole module array
+    return writer.copy(modules, 0, len(modules.SYMBOLS), 0)
+} //end func write
+func(writer *_moduleWriter) copy(modules *_modules, lo, hi, slab int) error {
+/*         Purpose : Appends a run of modules to a chunk.
+ *       Arguments : modules = module array.
+ *                   lo      = start of the run.
+ *                   hi      = end of the run.
+ *                   slab    = slab position of the first parameter at or after the start.
+ *         Returns : nil, or an error if the derivation exceeds its limit.
+ * Externals -  In : _pendingModules
+ * Externals - Out : None.
+ *       Functions : count
+ *         Remarks : None.
+ *         History : v1.24.0 - October 18, 2026 - Original release.
+ */
+    if writer.PENDING += hi - lo; writer.PENDING >= _pendingModules {
+        if err := writer.count(); err != nil { return err }
+    }
+    symbols := modules.SYMBOLS[lo:hi]
+    writer.MODULES.SYMBOLS = append(writer.MODULES.SYMBOLS, symbols...)
+    if len(modules.SLAB) > 0 {
+        params := bytes.Count(symbols, []byte{'('})
+        writer.MODULES.SLAB = append(writer.MODULES.SLAB, modules.SLAB[slab:slab+params]...)
+    }
+    return nil
+} //end func copy
+func(writer *_moduleWriter) count() error { //counts the pending modules against the limit
+    total, pending := &writer.DERIVATION.TOTAL, int64(writer.PENDING)
+    writer.PENDING  = 0
+    if atomic.AddInt64(total, pending) > int64(writer.DERIVATION.LIMIT) { return errors.New("the limit was exceeded") }
+    return nil
+} //end func count
+func derivePairs(order int, axiom string, oldnew []string, maxSymbols int) (turtleCmds string, err error) {
+/*         Purpose : Applies deterministic and context-free production rules, the earlier rules taking precedence.
+ *       Arguments : order      = derivation length.
+ *                   axiom      = production axiom.
+ *                   oldnew     = predecessor and successor pairs, as for strings.NewReplacer, with no empty predecessor.
+ *                   maxSymbols = most modules allowed in any derivation step, or 0 for no limit.
+ *         Returns : the turtle commands, or an error if a derivation step exceeds the limit.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : chunkCuts, literalModules, matchAt, newDerivation, step, turtleCmds
+ *         Remarks : - At each position, the first pair whose predecessor matches is applied, as by strings.Replacer,
+ *                     except that the heading declarations are single modules: their digits cannot be rewritten.
+ *                   - The cuts never separate two consecutive symbols of a predecessor, so that no predecessor can
+ *                     straddle two chunks.
+ *                   - A derivation step stops soon after it exceeds the limit, so that runaway growth costs little
+ *                     more than maxSymbols modules of memory.
+ *         History : v1.23.0 - October 18, 2026 - Original release, shared by Derive and DeterministicPairs.
+ *                   v1.24.0 - October 18, 2026 - Rewrote module arrays.
+ */
+    var( predecessors = make([]_modules, len(oldnew) / 2)
+         successors   = make([]_modules, len(oldnew) / 2)
+         first        [256][]int             //rules by the first symbol of their predecessor, in order of precedence
+         single       = make([]bool, len(oldnew) / 2) //true if the predecessor is a single symbol without parameter
+         inner        = make(map[[2]byte]bool)
+         derivation   = newDerivation(axiom, maxSymbols)
+         safe         func(pos int) bool
+    )
+    for k := range predecessors {
+        predecessors[k], successors[k] = literalModules(oldnew[2*k]), literalModules(oldnew[2*k+1])
+        symbols := predecessors[k].SYMBOLS
+        first[symbols[0]] = append(first[symbols[0]], k)
+        single[k]         = len(symbols) == 1 && len(predecessors[k].SLAB) == 0
+        //collect the pairs of consecutive symbols within the predecessors, which the chunk cuts must not separate
+        for j := 1; j < len(symbols); j++ {
+            inner[[2]byte{symbols[j-1], symbols[j]}] = true
+        }
+    }
+    //Apply the production rules
+    if maxSymbols > 0 && len(derivation.SOURCE.SYMBOLS) > maxSymbols {
+        return "", fmt.Errorf("the axiom exceeds %d symbols", maxSymbols)
+    }
+    for n := 1; n <= order; n++ {
+        source := &derivation.SOURCE
+        if len(inner) > 0 { safe = func(pos int) bool { return !inner[[2]byte{source.SYMBOLS[pos-1], source.SYMBOLS[pos]}] } }
+        cuts  := chunkCuts(len(source.SYMBOLS), safe)
+        err    = derivation.step(cuts, func(chunk, lo, hi, slab int, writer *_moduleWriter) error {
+                  run, runSlab := lo, slab //start of the run of modules left as they are
+                  for pos := lo; pos < hi; {
+                      matched := -1
+                      for _, k := range first[source.SYMBOLS[pos]] {
+                          if single[k] || source.matchAt(pos, slab, &predecessors[k]) { matched = k; break }
+                      }
+                      if matched < 0 {
+                          if source.SYMBOLS[pos] == '(' { slab++ }
+                          pos++
+                          continue
+                      }
+                      if err := writer.copy(source, run, pos, runSlab); err != nil { return err }
+                      if err := writer.write(&successors[matched]); err != nil { return err }
+                      pos, slab   = pos + len(predecessors[matched].SYMBOLS), slab + len(predecessors[matched].SLAB)
+                      run, runSlab = pos, slab
+                  }
+                  return writer.copy(source, run, hi, runSlab)
+              })
+        if err != nil { return "", fmt.Errorf("the order %d derivation exceeds %d symbols", n, maxSymbols) }
+    }
+    return derivation.SOURCE.turtleCmds(), nil
+} //end func derivePairs
+func rewriteContexts(derivation *_derivation, successors *[3][2][3]_modules) error {
+/*         Purpose : Applies one Hogeweg and Hesper derivation step concurrently.
+ *       Arguments : derivation = derivation whose modules are made of the symbols F + - $ [ ] 0 1
+ *                   successors = successors of "0" and "1" indexed by their left context, their symbol and their right
+ *                                context, the contexts being none, "0" or "1" in turn; no modules keep the symbol.
+ *         Returns : nil, or an error if a symbol is not supported or the branches are not balanced.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : chainContexts, chunkCuts, contextIndex, copy, forChunks, scanContexts, step, write
+ *         Remarks : The contexts are found in three passes:
+ *                   1) each chunk is scanned concurrently, without knowing the contexts at its ends, for the effect
+ *                      of its brackets on the context stacks;
+ *                   2) these effects are chained from chunk to chunk, giving the context stacks at every cut;
+ *                   3) each chunk is scanned again concurrently, both ways, from the stacks at its ends, and
+ *                      rewritten.
+ *                   The context of a symbol is thus found within its chunk, the chained stacks standing in for the
+ *                   rest of the modules whenever a bracket scan reaches a cut.
+ *         History : v1.23.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Rewrote module arrays.
+ */
+    var( source  = &derivation.SOURCE
+         symbols = source.SYMBOLS
+         cuts    = chunkCuts(len(symbols), nil)
+         chunks  = len(cuts) - 1
+         lefts   = make([][]int, chunks) //effects of the chunks on the left-context stack
+         rights  = make([][]int, chunks) //effects of the chunks on the right-context stack
+         popsL   = make([]int, chunks)
+         popsR   = make([]int, chunks)
+    )
+    //Scan the chunks for their effects on the context stacks
+    err := forChunks(chunks, func(k int) error {
+        for pos := cuts[k]; pos < cuts[k+1]; pos++ {
+            if strings.IndexByte("F[]$+-01", symbols[pos]) < 0 {
+                return fmt.Errorf("the symbol '%c' is not supported", symbols[pos])
+            }
+        }
+        lefts[k], popsL[k]  = scanContexts(symbols, cuts[k], cuts[k+1], true, nil, nil)
+        rights[k], popsR[k] = scanContexts(symbols, cuts[k], cuts[k+1], false, nil, nil)
+        return nil
+    })
+    if err != nil { return err }
+    //Chain the effects into the context stacks at the cuts
+    stacksL, okL := chainContexts(lefts, popsL, true)
+    stacksR, okR := chainContexts(rights, popsR, false)
+    if !okL || !okR { return errors.New("the branches are not balanced") }
+    //Rewrite the chunks
+    return derivation.step(cuts, func(chunk, lo, hi, slab int, writer *_moduleWriter) error {
+        var contextsL, contextsR []int //contexts of the 0s and 1s of the chunk, the right ones in reverse order
+        scanContexts(symbols, lo, hi, true, stacksL[chunk], func(context int) { contextsL = append(contextsL, context) })
+        scanContexts(symbols, lo, hi, false, stacksR[chunk], func(context int) { contextsR = append(contextsR, context) })
+        turns := _modules{SYMBOLS: []byte("-+")}
+        for pos, n := lo, 0; pos < hi; pos++ {
+            var err error
+            switch symbol := symbols[pos]; symbol {
+                case '+':
+                    err = writer.copy(&turns, 0, 1, 0)
+                case '-':
+                    err = writer.copy(&turns, 1, 2, 0)
+                case '0', '1':
+                    left, right := contextIndex(contextsL[n]), contextIndex(contextsR[len(contextsR)-1-n])
+                    successor   := &successors[left][symbol-'0'][right]
+                    if successor.SYMBOLS == nil {
+                        err = writer.copy(source, pos, pos + 1, 0)
+                    } else {
+                        err = writer.write(successor)
+                    }
+                    n++
+                default:
+                    err = writer.copy(source, pos, pos + 1, 0)
+            }
+            if err != nil { return err }
+        }
+        return nil
+    })
+} //end func rewriteContexts
+func scanContexts(symbols []byte, lo, hi int, forward bool, incoming []int, found func(context int)) (stack []int, popped int) {
+/*         Purpose : Tracks the Hogeweg and Hesper contexts of the symbols "0" and "1" through a chunk.
+ *       Arguments : symbols  = symbols being rewritten.
+ *                   lo       = start of the chunk.
+ *                   hi       = end of the chunk.
+ *                   forward  = true to scan left to right for the left contexts, false to scan right to left for the
+ *                              right contexts.
+ *                   incoming = context stack where the scan enters the chunk, the innermost branch last, or nil to
+ *                              stand for its entries symbolically.
+ *                   found    = function receiving the context of each "0" and "1" in scanning order, or nil.
+ *         Returns : the chunk's entries of the context stack where the scan leaves the chunk, and the number of
+ *                   entries of the incoming stack that they replace.
+ * Externals -  In : _noContext
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : - The top of the stack is the context of the next "0" or "1", which then takes its place.
+ *                   - The left context is the nearest 0 or 1 before the symbol on its branch, then on the branches it
+ *                     grows from, skipping the side branches. Scanning left to right, "[" thus pushes a copy of the
+ *                     top, which "]" pops.
+ *                   - The right context is the nearest 0 or 1 after the symbol on its branch, skipping the side
+ *                     branches, and none at the branch's end. Scanning right to left, "]" thus pushes no context,
+ *                     which "[" pops.
+ *                   - Symbolically, the incoming entry k places below the top is ^k, so that the chunks can be
+ *                     scanned before the stacks at their ends are known. With an actual incoming stack, the branches
+ *                     must be balanced, as checked by chainContexts.
+ *         History : v1.23.0 - October 18, 2026 - Original release.
+ */
+    entry := func(k int) int { //incoming entry k places below the top
+        if incoming == nil { return ^k }
+        return incoming[len(incoming)-1-k]
+    }
+    push, pop := byte('['), byte(']')
+    if !forward { push, pop = pop, push }
+    stack = []int{entry(0)}
+    for n := 0; n < hi - lo; n++ {
+        pos := lo + n
+        if !forward { pos = hi - 1 - n }
+        switch symbol := symbols[pos]; {
+            case symbol == push && forward: //a branch starts from the context of its mother branch
+                stack = append(stack, stack[len(stack)-1])
+            case symbol == push:            //a branch ends without a context
+                stack = append(stack, _noContext)
+            case symbol == pop:
+                if len(stack) > 1 {
+                    stack = stack[:len(stack)-1]
+                } else { //the branch started before the chunk
+                    popped++
+                    stack[0] = entry(popped)
+                }
+            case symbol == '0' || symbol == '1':
+                if found != nil { found(stack[len(stack)-1]) }
+                stack[len(stack)-1] = int(symbol)
+        }
+    }
+    return
+} //end func scanContexts
+func contextIndex(context int) int { //index of a context in the successor table of rewriteContexts
+    if context == _noContext { return 0 }
+    return context - '0' + 1
+} //end func contextIndex
+func chainContexts(effects [][]int, popped []int, forward bool) (stacks [][]int, ok bool) {
+/*         Purpose : Chains the effects of the chunks on a context stack into the stacks where the scans enter them.
+ *       Arguments : effects = entries of each chunk's outgoing stack, symbolic as returned by scanContexts.
+ *                   popped  = number of incoming entries replaced by each chunk's entries.
+ *                   forward = true for the left contexts, chained left to right, false for the right contexts.
+ *         Returns : the incoming stack of each chunk, and false if the branches are not balanced.
+ * Externals -  In : _noContext
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : The stacks are as deep as the branches open at the cuts.
+ *         History : v1.23.0 - October 18, 2026 - Original release.
+ */
+    var( chunks  = len(effects)
+         current = []int{_noContext}
+    )
+    stacks = make([][]int, chunks)
+    for n := 0; n < chunks; n++ {
+        k := map[bool]int{true: n, false: chunks - 1 - n} [forward]
+        if popped[k] >= len(current) { return nil, false } //a branch ends before it starts
+        stacks[k] = current
+        next     := append([]int(nil), current[:len(current)-1-popped[k]]...)
+        for _, entry := range effects[k] {
+            if entry < 0 { entry = current[len(current)-1-^entry] }
+            next = append(next, entry)
+        }
+        current = next
+    }
+    return stacks, len(current) == 1
+} //end func chainContexts
+func chunkRand(seed int64, step, length, chunk int) *rand.Rand {
+/*         Purpose : Creates the random stream of a chunk of a stochastic derivation step.
+ *       Arguments : seed   = derivation seed.
+ *                   step   = derivation step.
+ *                   length = number of modules being rewritten.
+ *                   chunk  = chunk number.
+ *         Returns : the random stream.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : The arguments are mixed by SplitMix64 finalizers, so that neighbouring chunks and steps draw
+ *                   unrelated streams.
+ *         History : v1.23.0 - October 18, 2026 - Original release.
+ */
+    mix := func(h, v uint64) uint64 {
+        h += v + 0x9e3779b97f4a7c15
+        h  = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+        h  = (h ^ (h >> 27)) * 0x94d049bb133111eb
+        return h ^ (h >> 31)
+    }
+    h := mix(mix(mix(uint64(seed), uint64(step)), uint64(length)), uint64(chunk))
+    return rand.New(rand.NewSource(int64(h >> 1)))
+} //end func chunkRand
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of parallel.go
@@ -0,0 +1,265 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      tests of the parallel rewriting engine against serial derivations.
+ *  Remarks:
+ *      The derivations run past several chunks of _derivationChunk symbols, so that the chunk cuts and the per-chunk
+ *      random streams are exercised.
+ *  History: v1.23.0 - October 18, 2026 - Original release.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "sort"
+    "strings"
+    "testing"
+)
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+var _testWorkers = []int{1, 2, 3, 8} //worker counts compared, the first one deriving serially
+func TestStochasticSeededWorkers(t *testing.T) {
+/*         Purpose : Checks that a seeded stochastic derivation is the same for any number of workers.
+ *       Arguments : t = test state.
+ *         Returns : None.
+ * Externals -  In : DerivationSeed, DerivationWorkers, ProgressBars, TurtleCmds, _derivationChunk, _testWorkers
+ * Externals - Out : DerivationSeed, DerivationWorkers, ProgressBars, TurtleCmds
+ *       Functions : Stochastic
+ *         Remarks : The package variables are restored on return.
+ *         History : v1.23.0 - October 18, 2026 - Original release.
+ */
+    defer func(seed int64, workers int, bars bool, turtleCmds string) {
+        DerivationSeed, DerivationWorkers, ProgressBars, TurtleCmds = seed, workers, bars, turtleCmds
+    }(DerivationSeed, DerivationWorkers, ProgressBars, TurtleCmds)
+
+    var( rules   = []string{"F[+F]F[-F]F", "F[+F]F", "F[-F]F"}
+         weights = []int{1, 1, 1}
+         serial  string
+    )
+    DerivationSeed, ProgressBars = 20161018, false
+    for _, workers := range _testWorkers {
+        DerivationWorkers = workers
+        Stochastic(9, "F", rules, weights)
+        if workers == _testWorkers[0] {
+            serial = TurtleCmds
+            if len(serial) < 3 * _derivationChunk {
+                t.Fatalf("the derivation has %d symbols, too few to span several chunks", len(serial))
+            }
+            continue
+        }
+        if TurtleCmds != serial {
+            t.Errorf("%d workers derived %d symbols differing from the serial derivation's %d", workers,
+                     len(TurtleCmds), len(serial))
+        }
+    }
+    return
+} //end func TestStochasticSeededWorkers
+func TestDeriveMatchesDeterministic(t *testing.T) {
+/*         Purpose : Checks that Derive matches Deterministic across the chunk cuts for any number of workers.
+ *       Arguments : t = test state.
+ *         Returns : None.
+ * Externals -  In : DerivationWorkers, ProgressBars, TurtleCmds, _derivationChunk, _testWorkers
+ * Externals - Out : DerivationWorkers, ProgressBars, TurtleCmds
+ *       Functions : Derive, Deterministic
+ *         Remarks : - The predecessor "FF" splits the runs of F differently if a cut falls inside them, so that the
+ *                     cuts must avoid separating consecutive Fs.
+ *                   - Deterministic is given the predecessors in the order Derive tries them, i.e., the longer ones
+ *                     first.
+ *                   - The package variables are restored on return.
+ *         History : v1.23.0 - October 18, 2026 - Original release.
+ */
+    defer func(workers int, bars bool, turtleCmds string) {
+        DerivationWorkers, ProgressBars, TurtleCmds = workers, bars, turtleCmds
+    }(DerivationWorkers, ProgressBars, TurtleCmds)
+
+    const( order = 16
+           axiom = "X"
+    )
+    var( rules        = map[string]string{"X": "XFFF+X", "FF": "F-F"}
+         oldnew       []string
+         predecessors []string
+    )
+    for predecessor := range rules {
+        predecessors = append(predecessors, predecessor)
+    }
+    sort.Slice(predecessors, func(i, j int) bool {
+        if len(predecessors[i]) != len(predecessors[j]) { return len(predecessors[i]) > len(predecessors[j]) }
+        return predecessors[i] < predecessors[j]
+    })
+    for _, predecessor := range predecessors {
+        oldnew = append(oldnew, predecessor, rules[predecessor])
+    }
+    ProgressBars = false
+    Deterministic(order, axiom, strings.NewReplacer(oldnew...))
+    if len(TurtleCmds) < 3 * _derivationChunk {
+        t.Fatalf("the derivation has %d symbols, too few to span several chunks", len(TurtleCmds))
+    }
+    for _, workers := range _testWorkers {
+        DerivationWorkers = workers
+        turtleCmds, err  := Derive(order, axiom, rules, 0)
+        switch {
+            case err != nil:
+                t.Errorf("%d workers: %v", workers, err)
+            case turtleCmds != TurtleCmds:
+                t.Errorf("%d workers derived %d symbols differing from Deterministic's %d", workers, len(turtleCmds),
+                         len(TurtleCmds))
+        }
+    }
+    return
+} //end func TestDeriveMatchesDeterministic
+func TestDeriveMatchesDeterministicHeadings(t *testing.T) {
+/*         Purpose : Checks that Derive rewrites the heading declarations as Deterministic does across the chunk cuts.
+ *       Arguments : t = test state.
+ *         Returns : None.
+ * Externals -  In : DerivationWorkers, ProgressBars, TurtleCmds, _derivationChunk, _testWorkers
+ * Externals - Out : DerivationWorkers, ProgressBars, TurtleCmds
+ *       Functions : Derive, Deterministic, literalModules
+ *         Remarks : - The headings are written in their shortest form, so that formatting the module arrays gives
+ *                     back the same turtle commands, and the predecessor "(30)F" matches a heading.
+ *                   - The chunks are counted in modules, a heading declaration being a single module.
+ *                   - The package variables are restored on return.
+ *         History : v1.24.0 - October 18, 2026 - Original release.
+ */
+    defer func(workers int, bars bool, turtleCmds string) {
+        DerivationWorkers, ProgressBars, TurtleCmds = workers, bars, turtleCmds
+    }(DerivationWorkers, ProgressBars, TurtleCmds)
+
+    const( order = 23
+           axiom = "X"
+    )
+    rules := map[string]string{"X": "X(30)FY", "Y": "(-45.5)F+X", "(30)F": "(60)FF"}
+    ProgressBars = false
+    Deterministic(order, axiom, strings.NewReplacer("(30)F", rules["(30)F"], "X", rules["X"], "Y", rules["Y"]))
+    if modules := literalModules(TurtleCmds); len(modules.SYMBOLS) < 4 * _derivationChunk {
+        t.Fatalf("the derivation has %d modules, too few for its last step to span several chunks", len(modules.SYMBOLS))
+    }
+    for _, workers := range _testWorkers {
+        DerivationWorkers = workers
+        turtleCmds, err  := Derive(order, axiom, rules, 0)
+        switch {
+            case err != nil:
+                t.Errorf("%d workers: %v", workers, err)
+            case turtleCmds != TurtleCmds:
+                t.Errorf("%d workers derived %d symbols differing from Deterministic's %d", workers, len(turtleCmds),
+                         len(TurtleCmds))
+        }
+    }
+    return
+} //end func TestDeriveMatchesDeterministicHeadings
+func TestDeterministicPairsMatchesDeterministic(t *testing.T) {
+/*         Purpose : Checks that DeterministicPairs matches Deterministic across the chunk cuts for any number of workers.
+ *       Arguments : t = test state.
+ *         Returns : None.
+ * Externals -  In : DerivationWorkers, ProgressBars, TurtleCmds, _derivationChunk, _testWorkers
+ * Externals - Out : DerivationWorkers, ProgressBars, TurtleCmds
+ *       Functions : Deterministic, DeterministicPairs
+ *         Remarks : - The predecessor "FF" comes before "F-", which the longest-first order of Derive would try first,
+ *                     so that "FF-" tells the two precedences apart. The cuts must separate neither pair.
+ *                   - The package variables are restored on return.
+ *         History : v1.23.0 - October 18, 2026 - Original release.
+ */
+    defer func(workers int, bars bool, turtleCmds string) {
+        DerivationWorkers, ProgressBars, TurtleCmds = workers, bars, turtleCmds
+    }(DerivationWorkers, ProgressBars, TurtleCmds)
+
+    const( order = 16
+           axiom = "X"
+    )
+    var( oldnew = []string{"X", "XFF+X", "FF", "F-F", "F-", "-F"}
+         serial string
+    )
+    ProgressBars = false
+    Deterministic(order, axiom, strings.NewReplacer(oldnew...))
+    serial = TurtleCmds
+    if len(serial) < 3 * _derivationChunk {
+        t.Fatalf("the derivation has %d symbols, too few to span several chunks", len(serial))
+    }
+    for _, workers := range _testWorkers {
+        DerivationWorkers = workers
+        DeterministicPairs(order, axiom, oldnew...)
+        if TurtleCmds != serial {
+            t.Errorf("%d workers derived %d symbols differing from Deterministic's %d", workers, len(TurtleCmds),
+                     len(serial))
+        }
+    }
+    return
+} //end func TestDeterministicPairsMatchesDeterministic
+func TestHogewegHesperMatchesSerialSearch(t *testing.T) {
+/*         Purpose : Checks that HogewegHesper matches a serial search of the contexts for any number of workers.
+ *       Arguments : t = test state.
+ *         Returns : None.
+ * Externals -  In : DerivationWorkers, ProgressBars, TurtleCmds, _derivationChunk, _testWorkers
+ * Externals - Out : DerivationWorkers, ProgressBars, TurtleCmds
+ *       Functions : HogewegHesper, searchContexts
+ *         Remarks : - The rules are those of figure 1.31a of The Algorithmic Beauty of Plants, whose branches nest
+ *                     deeply enough for the bracket contexts to cross many cuts.
+ *                   - The package variables are restored on return.
+ *         History : v1.23.0 - October 18, 2026 - Original release.
+ */
+    defer func(workers int, bars bool, turtleCmds string) {
+        DerivationWorkers, ProgressBars, TurtleCmds = workers, bars, turtleCmds
+    }(DerivationWorkers, ProgressBars, TurtleCmds)
+
+    const( order = 45
+           axiom = "$F1F1F1"
+    )
+    var( rules  = map[string]string{"0 < 0 > 0": "0", "0 < 0 > 1": "1[+F1F1]", "0 < 1 > 0": "1", "0 < 1 > 1": "1",
+                                    "1 < 0 > 0": "0", "1 < 0 > 1": "1F1",      "1 < 1 > 0": "0", "1 < 1 > 1": "0"}
+         serial = axiom
+    )
+    for n := 1; n <= order; n++ {
+        serial = searchContexts(serial, rules)
+    }
+    if len(serial) < 3 * _derivationChunk {
+        t.Fatalf("the derivation has %d symbols, too few to span several chunks", len(serial))
+    }
+    ProgressBars = false
+    for _, workers := range _testWorkers {
+        DerivationWorkers = workers
+        HogewegHesper(order, axiom, rules)
+        if TurtleCmds != serial {
+            t.Errorf("%d workers derived %d symbols differing from the serial search's %d", workers, len(TurtleCmds),
+                     len(serial))
+        }
+    }
+    return
+} //end func TestHogewegHesperMatchesSerialSearch
+func searchContexts(source string, rules map[string]string) string { //Hogeweg and Hesper step searching each context
+    var rewritten strings.Builder
+    for pos := 0; pos < len(source); pos++ {
+        symbol := source[pos]
+        switch symbol {
+            case '+':
+                rewritten.WriteByte('-')
+            case '-':
+                rewritten.WriteByte('+')
+            case '0', '1':
+                lhs, rhs := "", ""
+                for k, depth := pos - 1, 0; k >= 0 && lhs == ""; k-- { //skip the side branches, enter the mother's
+                    switch c := source[k]; {
+                        case c == ']':               depth++
+                        case c == '[' && depth > 0:  depth--
+                        case depth == 0 && (c == '0' || c == '1'): lhs = string(c)
+                    }
+                }
+                for k, depth := pos + 1, 0; k < len(source) && rhs == ""; k++ { //skip the side branches, stop at the end
+                    c := source[k]
+                    if c == ']' && depth == 0 { break }
+                    switch {
+                        case c == '[':               depth++
+                        case c == ']':               depth--
+                        case depth == 0 && (c == '0' || c == '1'): rhs = string(c)
+                    }
+                }
+                if successor, ok := rules[lhs + " < " + string(symbol) + " > " + rhs]; ok {
+                    rewritten.WriteString(successor)
+                } else {
+                    rewritten.WriteByte(symbol)
+                }
+            default:
+                rewritten.WriteByte(symbol)
+        }
+    }
+    return rewritten.String()
+} //end func searchContexts
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of parallel_test.go
@@ -0,0 +1,144 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      pure-Go rasterization of turtle geometry onto paletted images.
+ *  Remarks:
+ *      Line segments are drawn with Bresenham's algorithm using a square brush; polygons are filled with an even-odd
+ *      scanline algorithm. Palette index 0 is the background, the remaining indices are cycled by branch depth.
+ *  History: v1.1.0 - October 18, 2026 - Original release.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "image"
+    "image/color"
+    "math"
+    "sort"
+)
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+var _defaultPalette = color.Palette{color.White, color.Black}
+////Raster operations
+func makeFit2Page(xMin, xMax, yMin, yMax, width, height, margin float64, yDown bool) func(p _point) (x, y float64) {
+/*         Purpose : Makes a transform mapping turtle coordinates onto a page. The result is isometrically scaled and
+ *                   centered.
+ *       Arguments : xMin, xMax, yMin, yMax = bounding box of the turtle coordinates.
+ *                   width, height          = page size in device units.
+ *                   margin                 = blank border in device units.
+ *                   yDown                  = true if the device's y axis points down, as for raster images and SVG.
+ *         Returns : transform function.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : Degenerate spans are treated as unit spans.
+ *         History : v1.1.0 - October 18, 2026 - Original release.
+ */
+    xSpan, ySpan := math.Max(xMax - xMin, 1e-9), math.Max(yMax - yMin, 1e-9)
+    scale        := math.Min((width - 2*margin) / xSpan, (height - 2*margin) / ySpan)
+    if xMax == xMin && yMax == yMin { scale = 1. }
+    xShift       := 0.5 * (width  - scale * (xMax - xMin)) - scale * xMin
+    if yDown {
+        yShift := 0.5 * (height - scale * (yMax - yMin)) + scale * yMax
+        return func(p _point) (x, y float64) { return xShift + scale * p.X, yShift - scale * p.Y }
+    }
+    yShift := 0.5 * (height - scale * (yMax - yMin)) - scale * yMin
+    return func(p _point) (x, y float64) { return xShift + scale * p.X, yShift + scale * p.Y }
+} //end func makeFit2Page
+func makeFit2Canvas(xMin, xMax, yMin, yMax float64, width, height, margin int) func(p _point) (x, y int) {
+    fit := makeFit2Page(xMin, xMax, yMin, yMax, float64(width), float64(height), float64(margin), true)
+    return func(p _point) (x, y int) {
+            xf, yf := fit(p)
+            return int(math.Floor(xf + 0.5)), int(math.Floor(yf + 0.5))
+           }
+} //end func makeFit2Canvas
+func drawGeometry(canvas *image.Paletted, geometry *_geometry, fit func(p _point) (x, y int), lineWidth int,
+                  segments int) {
+/*         Purpose : Draws turtle geometry onto a paletted canvas.
+ *       Arguments : canvas    = destination image.
+ *                   geometry  = turtle geometry.
+ *                   fit       = transform from turtle coordinates to pixels.
+ *                   lineWidth = line width in pixels.
+ *                   segments  = number of line segments to draw in turtle order (negative for all of them).
+ *         Returns : None.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : drawLine, fillPolygon
+ *         Remarks : - Polygons are filled with palette index 1 once all the segments preceding their closure are drawn.
+ *                   - Segments whose Horton-Strahler order is assigned are drawn that many times thicker.
+ *         History : v1.1.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Added the line width by Horton-Strahler order.
+ */
+    numColors := len(canvas.Palette) - 1
+    if segments < 0 || segments > len(geometry.SEGMENTS) { segments = len(geometry.SEGMENTS) }
+    for _, polygon := range geometry.POLYGONS {
+        if polygon.AFTER > segments { break }
+        var xs, ys []int
+        for _, vertex := range polygon.VERTICES {
+            x, y := fit(vertex)
+            xs    = append(xs, x)
+            ys    = append(ys, y)
+        }
+        fillPolygon(canvas, xs, ys, 1)
+        for k := range xs {
+            drawLine(canvas, xs[k], ys[k], xs[(k+1) % len(xs)], ys[(k+1) % len(ys)], lineWidth, 1)
+        }
+    }
+    for _, segment := range geometry.SEGMENTS[:segments] {
+        x0, y0 := fit(segment.FROM)
+        x1, y1 := fit(segment.TO)
+        drawLine(canvas, x0, y0, x1, y1, lineWidth * map[bool]int{true: segment.ORDER, false: 1} [segment.ORDER > 0],
+                 uint8(1 + segment.DEPTH % numColors))
+    }
+    return
+} //end func drawGeometry
+func drawLine(canvas *image.Paletted, x0, y0, x1, y1, lineWidth int, colorIdx uint8) {
+    dx, dy := x1 - x0, y1 - y0
+    if dx < 0 { dx = -dx }
+    if dy < 0 { dy = -dy }
+    sx, sy := map[bool]int{true: 1, false: -1} [x0 < x1], map[bool]int{true: 1, false: -1} [y0 < y1]
+    err    := dx - dy
+    lo     := -(lineWidth - 1) / 2
+    hi     := lo + lineWidth - 1
+    for {
+        for bx := lo; bx <= hi; bx++ { //square brush
+            for by := lo; by <= hi; by++ {
+                canvas.SetColorIndex(x0 + bx, y0 + by, colorIdx)
+            }
+        }
+        if x0 == x1 && y0 == y1 { break }
+        e2 := 2 * err
+        if e2 > -dy { err -= dy; x0 += sx }
+        if e2 <  dx { err += dx; y0 += sy }
+    }
+    return
+} //end func drawLine
+func fillPolygon(canvas *image.Paletted, xs, ys []int, colorIdx uint8) {
+    bounds := canvas.Bounds()
+    yMin, yMax := ys[0], ys[0]
+    for _, y := range ys {
+        if y < yMin { yMin = y }
+        if y > yMax { yMax = y }
+    }
+    if yMin < bounds.Min.Y   { yMin = bounds.Min.Y }
+    if yMax > bounds.Max.Y-1 { yMax = bounds.Max.Y-1 }
+    for y := yMin; y <= yMax; y++ {
+        var crossings []int
+        yc := float64(y) + 0.5 //sample at the pixel centers
+        for k := range xs {
+            xa, ya := float64(xs[k]), float64(ys[k])
+            xb, yb := float64(xs[(k+1) % len(xs)]), float64(ys[(k+1) % len(ys)])
+            if (ya <= yc) != (yb <= yc) {
+                crossings = append(crossings, int(math.Floor(xa + (yc - ya) * (xb - xa) / (yb - ya) + 0.5)))
+            }
+        }
+        sort.Ints(crossings)
+        for k := 0; k + 1 < len(crossings); k += 2 { //even-odd rule
+            for x := crossings[k]; x < crossings[k+1]; x++ {
+                canvas.SetColorIndex(x, y, colorIdx)
+            }
+        }
+    }
+    return
+} //end func fillPolygon
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of raster.go
@@ -0,0 +1,322 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      error-returning derivation and rendering for long-running programs such as servers.
+ *  Types:
+ *      RenderOptions
+ *          Interpretation settings of the rendering functions: exact turtle, vertex welding, pen-travel optimisation
+ *          and line width by Horton-Strahler order.
+ *  Functions:
+ *      Derive(order int, axiom string, rules map[string]string, maxSymbols int) (turtleCmds string, err error)
+ *          Returns the turtle commands for the specified deterministic and context-free production parameters, failing
+ *          if they would exceed the given number of symbols.
+ *      RenderSvg(turtleCmds string, angle float64, width, height int, lineWidth float64, lineColor, bgColor string,
+ *                options RenderOptions) ([]byte, error)
+ *          Renders turtle commands as a static SVG image. The drawing is isometrically scaled and centered.
+ *      RenderPng(turtleCmds string, angle float64, width, height, lineWidth int, palette color.Palette,
+ *                options RenderOptions) ([]byte, error)
+ *          Renders turtle commands as a PNG image. The drawing is isometrically scaled and centered.
+ *      RenderHpgl(turtleCmds string, angle float64, plotTitle string, penWidth float64, media HpglMedia,
+ *                 options RenderOptions) ([]byte, error)
+ *          Converts turtle commands to an HP-GL/2 command set. The resulting plot will be isometrically scaled and
+ *          centered on the media.
+ *  Remarks:
+ *      Unlike the rest of the package, these functions neither read nor write TurtleCmds and report invalid arguments
+ *      and malformed turtle commands as errors instead of halting, so that they may be called concurrently. The
+ *      rendering functions take their settings from RenderOptions instead of ExactTurtle, OptimizePenTravel,
+ *      WeldVertices and WeldTolerance, and never display progress bars.
+ *  History: v1.14.0 - October 18, 2026 - Original release.
+ *           v1.22.0 - October 18, 2026 - Added vertex welding.
+ *           v1.23.0 - October 18, 2026 - Added parallel derivation to Derive.
+ *           v1.24.0 - October 18, 2026 - Interpreted the checked turtle commands as module arrays.
+ *                                         Passed the rendering settings as RenderOptions.
+ *                                         Added the line width by Horton-Strahler order to RenderSvg and RenderPng.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "bytes"
+    "errors"
+    "fmt"
+    "image"
+    "image/color"
+    "image/png"
+    "sort"
+    "strings"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type RenderOptions struct {
+    EXACT_TURTLE    bool    //interpret the turtle commands exactly whenever the headings divide the full turn
+    OPTIMIZE_TRAVEL bool    //merge and reorder the HP-GL/2 strokes to minimise the pen-up travel
+    WELD_VERTICES   bool    //weld the vertices and merge the line segments into polylines before rendering
+    WELD_TOLERANCE  float64 //turtle strides - vertices closer than this are welded; 0 for 1e-6
+    WIDTH_BY_ORDER  bool    //multiply the SVG and PNG line width of each segment by its Horton-Strahler order
+}
+func Derive(order int, axiom string, rules map[string]string, maxSymbols int) (turtleCmds string, err error) {
+/*         Purpose : Returns the turtle commands for the specified deterministic and context-free production parameters,
+ *                   failing if they would exceed the given number of symbols.
+ *       Arguments : order      = order of the curve, that is, the derivation length of the production rules.
+ *                                (The zeroth order corresponds to the axiom.)
+ *                   axiom      = production axiom.
+ *                   rules      = production rules keyed by their predecessor.
+ *                   maxSymbols = most symbols allowed in any derivation step, or 0 for no limit, a heading declaration
+ *                                counting as one symbol.
+ *         Returns : the turtle commands, or an error.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : derivePairs, rulePairs
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                   - Pseudo-L-systems are supported. Longer predecessors take precedence over the shorter ones they
+ *                     start with, unlike DeterministicPairs which applies the first of its rules that matches.
+ *                   - A derivation step stops soon after it exceeds the limit, so that runaway growth costs little
+ *                     more than maxSymbols modules of memory.
+ *                   - Each derivation step is shared among DerivationWorkers goroutines.
+ *                   - The derivation rewrites module arrays, the heading declarations being single modules formatted
+ *                     in their shortest form in the result.
+ *         History : v1.14.0 - October 18, 2026 - Original release.
+ *                   v1.23.0 - October 18, 2026 - Added parallel derivation, shared with DeterministicPairs.
+ *                   v1.24.0 - October 18, 2026 - Rewrote module arrays.
+ */
+    if order < 0      { return "", errors.New("curve order must be non-negative") }
+    if axiom == ""    { return "", errors.New("axiom was not specified") }
+    if maxSymbols < 0 { return "", errors.New("the symbol limit must be non-negative") }
+
+    oldnew, err := rulePairs(rules, nil) //the longer predecessors first
+    if err != nil { return "", err }
+    return derivePairs(order, axiom, oldnew, maxSymbols)
+} //end func Derive
+func RenderSvg(turtleCmds string, angle float64, width, height int, lineWidth float64, lineColor, bgColor string,
+               options RenderOptions) ([]byte, error) {
+/*         Purpose : Renders turtle commands as a static SVG image. The drawing is isometrically scaled and centered.
+ *       Arguments : turtleCmds = turtle commands.
+ *                   angle      = production angle in degrees.
+ *                   width      = image width in pixels.
+ *                   height     = image height in pixels.
+ *                   lineWidth  = line width in pixels.
+ *                   lineColor  = color of the line segments and polygons, as recognized by SVG.
+ *                   bgColor    = background color, as recognized by SVG, or "" for a transparent background.
+ *                   options    = interpretation settings.
+ *         Returns : the SVG document, or an error.
+ * Externals -  In : _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : assignStrahler, chainSegments, checkOptions, checkTurtleCmds, makeFit2Page, modules2Geometry,
+ *                   splitSegments, weldWithin
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - Each continuous run of the pen becomes a path, with its own stroke width if the width is set by
+ *                     order.
+ *         History : v1.14.0 - October 18, 2026 - Original release.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ *                   v1.24.0 - October 18, 2026 - Interpreted the checked module array with the RenderOptions settings.
+ *                                                 Added the line width by Horton-Strahler order.
+ */
+    modules, err := checkTurtleCmds(turtleCmds)
+    if err != nil                  { return nil, err }
+    if angle == 0.                 { return nil, errors.New("the production angle is zero") }
+    if width < 1 || height < 1     { return nil, errors.New("the image size must be positive") }
+    if !(lineWidth > 0.)           { return nil, errors.New("the line width must be positive") }
+    if !validSvgColor(lineColor)   { return nil, errors.New("the line color is not valid") }
+    if bgColor != "" && !validSvgColor(bgColor) { return nil, errors.New("the background color is not valid") }
+    if err := checkOptions(&options); err != nil { return nil, err }
+
+    const margin = 4. //pixels
+    var buffer bytes.Buffer
+    //Interpret the turtle commands
+    geometry := modules2Geometry(&modules, angle, nil, options.EXACT_TURTLE, false)
+    if options.WIDTH_BY_ORDER { assignStrahler(&geometry) }
+    if options.WELD_VERTICES  { weldWithin(&geometry, options.WELD_TOLERANCE) }
+    fit      := makeFit2Page(geometry.XMIN, geometry.XMAX, geometry.YMIN, geometry.YMAX,
+                             float64(width), float64(height), margin, true)
+    //Compose the SVG document
+    fmt.Fprintf(&buffer, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+    fmt.Fprintf(&buffer, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+                width, height, width, height)
+    if bgColor != "" { fmt.Fprintf(&buffer, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", bgColor) }
+    fmt.Fprintf(&buffer, `<g fill="%s" stroke="%s" stroke-width="%g" stroke-linecap="round" stroke-linejoin="round">`+"\n",
+                lineColor, lineColor, lineWidth)
+    for _, polygon := range geometry.POLYGONS {
+        buffer.WriteString(`<polygon fill-rule="evenodd" points="`)
+        for k, vertex := range polygon.VERTICES {
+            x, y := fit(vertex)
+            fmt.Fprintf(&buffer, map[bool]string{true: "%.2f,%.2f", false: " %.2f,%.2f"} [k == 0], x, y)
+        }
+        buffer.WriteString(`"/>` + "\n")
+    }
+    for _, run := range splitSegments(geometry.SEGMENTS, func(depth, color, order int) int { return order }, false) {
+        stroke := map[bool]string{true: fmt.Sprintf(` stroke-width="%g"`, lineWidth * float64(run.KEY)),
+                                  false: ""} [run.KEY > 0]
+        for _, polyline := range chainSegments(run.SEGMENTS) {
+            x, y := fit(polyline[0])
+            fmt.Fprintf(&buffer, `<path fill="none"%s d="M%.2f,%.2f`, stroke, x, y)
+            for _, vertex := range polyline[1:] {
+                x, y = fit(vertex)
+                fmt.Fprintf(&buffer, "L%.2f,%.2f", x, y)
+            }
+            buffer.WriteString(`"/>` + "\n")
+        }
+    }
+    fmt.Fprintf(&buffer, "</g>\n</svg>\n")
+    return buffer.Bytes(), nil
+} //end func RenderSvg
+func RenderPng(turtleCmds string, angle float64, width, height, lineWidth int, palette color.Palette,
+               options RenderOptions) ([]byte, error) {
+/*         Purpose : Renders turtle commands as a PNG image. The drawing is isometrically scaled and centered.
+ *       Arguments : turtleCmds = turtle commands.
+ *                   angle      = production angle in degrees.
+ *                   width      = image width in pixels.
+ *                   height     = image height in pixels.
+ *                   lineWidth  = line width in pixels.
+ *                   palette    = background color followed by the line colors cycled by branch depth, or nil for black on
+ *                                white.
+ *                   options    = interpretation settings.
+ *         Returns : the PNG image, or an error.
+ * Externals -  In : _defaultPalette, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : assignStrahler, checkOptions, checkTurtleCmds, drawGeometry, makeFit2Canvas, modules2Geometry,
+ *                   weldWithin
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *         History : v1.14.0 - October 18, 2026 - Original release.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ *                   v1.24.0 - October 18, 2026 - Interpreted the checked module array with the RenderOptions settings.
+ *                                                 Added the line width by Horton-Strahler order.
+ */
+    modules, err := checkTurtleCmds(turtleCmds)
+    if err != nil                    { return nil, err }
+    if angle == 0.                   { return nil, errors.New("the production angle is zero") }
+    if width < 1 || height < 1       { return nil, errors.New("the image size must be positive") }
+    if lineWidth < 1                 { return nil, errors.New("the line width must be positive") }
+    if palette == nil                { palette = _defaultPalette }
+    if len(palette) < 2 || len(palette) > 256 { return nil, errors.New("the palette must have 2 to 256 colors") }
+    if err := checkOptions(&options); err != nil { return nil, err }
+
+    const margin = 4 //pixels
+    var buffer bytes.Buffer
+    //Interpret the turtle commands and draw them
+    geometry := modules2Geometry(&modules, angle, nil, options.EXACT_TURTLE, false)
+    if options.WIDTH_BY_ORDER { assignStrahler(&geometry) }
+    if options.WELD_VERTICES  { weldWithin(&geometry, options.WELD_TOLERANCE) }
+    canvas   := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+    drawGeometry(canvas, &geometry, makeFit2Canvas(geometry.XMIN, geometry.XMAX, geometry.YMIN, geometry.YMAX,
+                                                   width, height, margin), lineWidth, -1)
+    //Encode the image
+    if err := png.Encode(&buffer, canvas); err != nil { return nil, errors.New("png.Encode - " + err.Error()) }
+    return buffer.Bytes(), nil
+} //end func RenderPng
+func RenderHpgl(turtleCmds string, angle float64, plotTitle string, penWidth float64, media HpglMedia,
+                options RenderOptions) ([]byte, error) {
+/*         Purpose : Converts turtle commands to an HP-GL/2 command set. The resulting plot will be isometrically scaled
+ *                   and centered on the media.
+ *       Arguments : turtleCmds = turtle commands.
+ *                   angle      = production angle in degrees.
+ *                   plotTitle  = title to be centered at the top of the plot.
+ *                   penWidth   = line-width in millimeters, unless the pen widths are set.
+ *                   media      = media and device settings.
+ *                   options    = interpretation settings.
+ *         Returns : the HP-GL/2 commands, or an error.
+ * Externals -  In : _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : assignStrahler, checkMedia, checkOptions, checkTurtleCmds, hpglPlotCmds, modules2Geometry,
+ *                   weldWithin
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { } ; ,
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - PenTravel is left untouched by the pen-travel optimisation.
+ *         History : v1.14.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Passed the settings as RenderOptions.
+ */
+    modules, err := checkTurtleCmds(turtleCmds)
+    if err != nil                                { return nil, err }
+    if angle == 0.                               { return nil, errors.New("the production angle is zero") }
+    if err := checkMedia(media); err != nil     { return nil, err }
+    if err := checkOptions(&options); err != nil { return nil, err }
+
+    var report *TravelReport
+    if options.OPTIMIZE_TRAVEL { report = &TravelReport{} }
+    //Interpret the turtle commands using unit turtle strides and compose the plot
+    geometry := modules2Geometry(&modules, angle, nil, options.EXACT_TURTLE, false)
+    if media.PEN_BY == PenByStrahler { assignStrahler(&geometry) }
+    if options.WELD_VERTICES         { weldWithin(&geometry, options.WELD_TOLERANCE) }
+    return []byte(hpglPlotCmds(&geometry, plotTitle, penWidth, media, report)), nil
+} //end func RenderHpgl
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+func rulePairs(rules map[string]string, listed []string) (oldnew []string, err error) {
+/*         Purpose : Orders deterministic production rules by precedence for derivePairs.
+ *       Arguments : rules  = production rules keyed by their predecessor.
+ *                   listed = predecessors taking precedence over the others, in the given order, or nil.
+ *         Returns : the predecessor and successor pairs, or an error if a predecessor is empty, or listed twice or
+ *                   without a rule.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : The predecessors that are not listed follow, the longer ones first and then in lexicographic order.
+ *         History : v1.15.0 - October 18, 2026 - Original release, split from Derive.
+ */
+    var( predecessors []string
+         seen         = make(map[string]bool, len(listed))
+    )
+    for _, predecessor := range listed {
+        if _, ok := rules[predecessor]; !ok || seen[predecessor] {
+            return nil, fmt.Errorf("the listed predecessor %q is repeated or has no rule", predecessor)
+        }
+        seen[predecessor] = true
+        oldnew            = append(oldnew, predecessor, rules[predecessor])
+    }
+    for predecessor := range rules {
+        if predecessor == "" { return nil, errors.New("the rules were not specified correctly") }
+        if !seen[predecessor] { predecessors = append(predecessors, predecessor) }
+    }
+    sort.Slice(predecessors, func(i, j int) bool {
+        if len(predecessors[i]) != len(predecessors[j]) { return len(predecessors[i]) > len(predecessors[j]) }
+        return predecessors[i] < predecessors[j]
+    })
+    for _, predecessor := range predecessors {
+        oldnew = append(oldnew, predecessor, rules[predecessor])
+    }
+    return oldnew, nil
+} //end func rulePairs
+func checkTurtleCmds(turtleCmds string) (modules _modules, err error) {
+/*         Purpose : Checks that turtle commands can be interpreted without halting.
+ *       Arguments : turtleCmds = turtle commands.
+ *         Returns : the turtle commands as a module array, or an error describing the first problem found.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : parseModules
+ *         Remarks : The heading declarations must be well-formed and no branch may end before it starts.
+ *         History : v1.14.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Returned the module array.
+ */
+    if turtleCmds == "" { return modules, errors.New("the turtle commands were not generated") }
+    if modules, err = parseModules(turtleCmds); err != nil { return }
+    depth := 0
+    for _, symbol := range modules.SYMBOLS {
+        switch symbol {
+            case '[':
+                depth++
+            case ']':
+                if depth == 0 { return modules, errors.New("a branch ends before it starts") }
+                depth--
+        }
+    }
+    return
+} //end func checkTurtleCmds
+func checkOptions(options *RenderOptions) error {
+    //substitutes the default weld tolerance for 0 before validating it
+    if options.WELD_TOLERANCE == 0. { options.WELD_TOLERANCE = _weldTolerance }
+    return checkWeld(options.WELD_VERTICES, options.WELD_TOLERANCE)
+} //end func checkOptions
+func validSvgColor(svgColor string) bool {
+    //guards the attribute values against markup injection; the renderer ignores unknown color names
+    if svgColor == "" { return false }
+    for _, r := range svgColor {
+        if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("#(),.% ", r)) {
+            return false
+        }
+    }
+    return true
+} //end func validSvgColor
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of render.go
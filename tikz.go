@@ -0,0 +1,195 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      TikZ/PGF output for LaTeX documents.
+ *  Types:
+ *      TikzSettings
+ *          Picture settings for TikZ output.
+ *  Functions:
+ *      TikzPlot(angle float64, plotTitle string, settings TikzSettings, texPath string)
+ *          Converts the latest generated turtle commands to a tikzpicture environment.
+ *      TikzGridPlot(turtleCmds []string, turtleAngles []float64, layout GridLayout, plotTitle string,
+ *                   titles, labels []string, settings TikzSettings, texPath string)
+ *          Converts a set of turtle commands on a grid to a tikzpicture environment. Each subplot is scaled and centered
+ *          in its cell according to the layout's scaling mode.
+ *  Remarks:
+ *      The coordinates are written in drawing units, the picture being scaled through its x and y options so that the
+ *      line widths and text sizes are unaffected. The output requires \usepackage{tikz} (which loads xcolor), unless
+ *      a standalone document is requested.
+ *  History: v1.12.0 - October 18, 2026 - Original release.
+ *           v1.22.0 - October 18, 2026 - Added vertex welding.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "bytes"
+    "fmt"
+    "image/color"
+    "math"
+    "strings"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type TikzSettings struct {
+    WIDTH      float64     //width of the picture in centimeters; 0 for 1 cm per turtle stride or 5 cm per grid cell
+    LINE_WIDTH float64     //line width in points; 0 for TikZ's default of 0.4 pt
+    LINE_COLOR color.Color //color of the lines; nil for black
+    FILL_COLOR color.Color //color of the polygons; nil for the line color
+    STANDALONE bool        //true to wrap the picture in a standalone LaTeX document
+}
+func TikzPlot(angle float64, plotTitle string, settings TikzSettings, texPath string) {
+/*         Purpose : Converts the latest generated turtle commands to a tikzpicture environment.
+ *       Arguments : angle     = production angle in degrees.
+ *                   plotTitle = title to be centered above the drawing.
+ *                   settings  = picture settings.
+ *                   texPath   = file path for the LaTeX code.
+ *         Returns : None.
+ * Externals -  In : TurtleCmds, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : escapeLatex, fileWrite, geometry2Tikz, halt, logo2Geometry, tikzPicture, weldGeometry
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *         History : v1.12.0 - October 18, 2026 - Original release.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ */
+    if TurtleCmds == ""         { halt("the turtle commands were not generated") }
+    if angle      == 0.         { halt("the production angle is zero") }
+    if settings.WIDTH < 0.      { halt("the picture width must be non-negative") }
+    if settings.LINE_WIDTH < 0. { halt("the line width must be non-negative") }
+    if texPath    == ""         { halt("the path for the LaTeX code was not specified") }
+
+    var buffer bytes.Buffer
+    //Interpret the turtle commands
+    geometry := logo2Geometry(TurtleCmds, angle, nil)
+    weldGeometry(&geometry)
+    scale    := 1.
+    if settings.WIDTH > 0. { scale = settings.WIDTH / math.Max(geometry.XMAX - geometry.XMIN, 1e-9) }
+    //Compose the picture
+    buffer.WriteString(geometry2Tikz(&geometry, func(p _point) _point { return p }))
+    if plotTitle != "" {
+        fmt.Fprintf(&buffer, "\\node[anchor=south] at (%.4f,%.4f) {%s};\n", 0.5 * (geometry.XMIN + geometry.XMAX),
+                    geometry.YMAX + 0.3 / scale, escapeLatex(plotTitle))
+    }
+    //Output the LaTeX code to the specified destination
+    fileWrite(texPath, tikzPicture(settings, scale, buffer.String()))
+    return
+} //end func TikzPlot
+func TikzGridPlot(turtleCmds []string, turtleAngles []float64, layout GridLayout, plotTitle string,
+                  titles, labels []string, settings TikzSettings, texPath string) {
+/*         Purpose : Converts a set of turtle commands on a grid to a tikzpicture environment. Each subplot is scaled and
+ *                   centered in its cell according to the layout's scaling mode.
+ *       Arguments : turtleCmds   = slice of turtle commands.
+ *                   turtleAngles = slice of production angles in degrees.
+ *                   layout       = grid layout. Use one row to generate the subplots left to right, as MultiPlot does.
+ *                   plotTitle    = title to be centered above the grid.
+ *                   titles       = slice of titles to be centered above each subplot, or nil.
+ *                   labels       = slice of labels to be centered below each subplot, or nil.
+ *                   settings     = picture settings.
+ *                   texPath      = file path for the LaTeX code.
+ *         Returns : None.
+ * Externals -  In : _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : escapeLatex, fileWrite, geometry2Tikz, halt, layoutGrid, logo2Geometry, tikzPicture, validGrid,
+ *                   weldGeometry
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - The subplots fill the grid row by row, from the top left cell.
+ *         History : v1.12.0 - October 18, 2026 - Original release.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ */
+    validGrid(turtleCmds, turtleAngles, layout, titles, labels)
+    if settings.WIDTH < 0.      { halt("the picture width must be non-negative") }
+    if settings.LINE_WIDTH < 0. { halt("the line width must be non-negative") }
+    if texPath == ""            { halt("the path for the LaTeX code was not specified") }
+
+    const cellSize = 5. //cm - default size of a grid cell
+    var( buffer     bytes.Buffer
+         geometries = make([]_geometry, len(turtleCmds))
+    )
+    //Interpret the turtle commands and lay out the subplots
+    for k, v := range turtleCmds {
+        geometries[k] = logo2Geometry(v, turtleAngles[k], nil)
+        weldGeometry(&geometries[k])
+    }
+    cells, columns, rows := layoutGrid(geometries, layout, titles, labels)
+    scale := cellSize
+    if settings.WIDTH > 0. { scale = settings.WIDTH / float64(columns) }
+    //Compose the picture
+    for k := range geometries {
+        buffer.WriteString(geometry2Tikz(&geometries[k], cells[k].FIT))
+        if titles != nil && titles[k] != "" {
+            fmt.Fprintf(&buffer, "\\node at (%.4f,%.4f) {%s};\n", cells[k].TITLE.X, cells[k].TITLE.Y, escapeLatex(titles[k]))
+        }
+        if labels != nil && labels[k] != "" {
+            fmt.Fprintf(&buffer, "\\node at (%.4f,%.4f) {%s};\n", cells[k].LABEL.X, cells[k].LABEL.Y, escapeLatex(labels[k]))
+        }
+    }
+    if plotTitle != "" {
+        fmt.Fprintf(&buffer, "\\node[anchor=south] at (%.4f,%.4f) {%s};\n", 0.5 * float64(columns), float64(rows),
+                    escapeLatex(plotTitle))
+    }
+    //Output the LaTeX code to the specified destination
+    fileWrite(texPath, tikzPicture(settings, scale, buffer.String()))
+    return
+} //end func TikzGridPlot
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+func tikzPicture(settings TikzSettings, scale float64, body string) string {
+/*         Purpose : Wraps TikZ commands in a tikzpicture environment defining the colors and line style.
+ *       Arguments : settings = picture settings.
+ *                   scale    = length in centimeters of a drawing unit.
+ *                   body     = TikZ commands.
+ *         Returns : the LaTeX code.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : The colors are named lsline and lsfill.
+ *         History : v1.12.0 - October 18, 2026 - Original release.
+ */
+    var buffer bytes.Buffer
+    lineColor, fillColor := settings.LINE_COLOR, settings.FILL_COLOR
+    if lineColor == nil { lineColor = color.Black }
+    if fillColor == nil { fillColor = lineColor }
+    rgb := func(c color.Color) string {
+        r, g, b, _ := c.RGBA()
+        return fmt.Sprintf("%d,%d,%d", r >> 8, g >> 8, b >> 8)
+    }
+    if settings.STANDALONE { buffer.WriteString("\\documentclass[tikz]{standalone}\n\\begin{document}\n") }
+    fmt.Fprintf(&buffer, "\\begin{tikzpicture}[x=%.6fcm,y=%.6fcm", scale, scale)
+    if settings.LINE_WIDTH > 0. { fmt.Fprintf(&buffer, ",line width=%gpt", settings.LINE_WIDTH) }
+    buffer.WriteString(",line cap=round,line join=round]\n")
+    fmt.Fprintf(&buffer, "\\definecolor{lsline}{RGB}{%s}\n\\definecolor{lsfill}{RGB}{%s}\n", rgb(lineColor), rgb(fillColor))
+    buffer.WriteString(body)
+    buffer.WriteString("\\end{tikzpicture}\n")
+    if settings.STANDALONE { buffer.WriteString("\\end{document}\n") }
+    return buffer.String()
+} //end func tikzPicture
+func geometry2Tikz(geometry *_geometry, fit func(p _point) _point) string {
+    const perLine = 6 //coordinates per line of code
+    var buffer bytes.Buffer
+    path := func(vertices []_point) {
+        for k, vertex := range vertices {
+            p := fit(vertex)
+            if k > 0 { buffer.WriteString(map[bool]string{true: "\n    -- ", false: " -- "} [k % perLine == 0]) }
+            fmt.Fprintf(&buffer, "(%.4f,%.4f)", p.X, p.Y)
+        }
+    }
+    for _, polygon := range geometry.POLYGONS {
+        buffer.WriteString("\\filldraw[even odd rule,draw=lsline,fill=lsfill] ")
+        path(polygon.VERTICES)
+        buffer.WriteString(" -- cycle;\n")
+    }
+    for _, polyline := range chainSegments(geometry.SEGMENTS) {
+        buffer.WriteString("\\draw[lsline] ")
+        path(polyline)
+        buffer.WriteString(";\n")
+    }
+    return buffer.String()
+} //end func geometry2Tikz
+func escapeLatex(text string) string {
+    return strings.NewReplacer(`\`, `\textbackslash{}`, `{`, `\{`, `}`, `\}`, `$`, `\$`, `&`, `\&`, `#`, `\#`,
+                               `%`, `\%`, `_`, `\_`, `^`, `\textasciicircum{}`, `~`, `\textasciitilde{}`).Replace(text)
+} //end func escapeLatex
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of tikz.go
@@ -0,0 +1,189 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      timed (developmental) L-systems rendering continuous growth at arbitrary fractional times.
+ *  Types:
+ *      TimedModule
+ *          Module of a timed L-system: a symbol and its age.
+ *      TimedRule
+ *          Production of a timed L-system: the terminal age of the predecessor and its successor modules.
+ *  Functions:
+ *      GifGrowth(axiom []TimedModule, rules map[string]TimedRule, growth func(age, terminalAge float64) float64,
+ *                angle, tStart, tEnd float64, numFrames, width, height, lineWidth, delay int, palette color.Palette,
+ *                gifPath string)
+ *          Renders the development of a timed L-system between two points in time as an animated GIF. The frames are
+ *          isometrically scaled and centered to their common bounding box.
+ *      LinearGrowth(age, terminalAge float64) float64
+ *          Growth function increasing linearly from 0 to 1 over the lifetime of a module.
+ *      LogisticGrowth(age, terminalAge float64) float64
+ *          Growth function following a logistic (sigmoid) curve from 0 to 1 over the lifetime of a module.
+ *  Remarks:
+ *      See Prusinkiewicz, P. and Lindenmayer, A. (1990) "The Algorithmic Beauty of Plants", Springer-Verlag, Chapter 6,
+ *      (http://algorithmicbotany.org/papers/abop/abop.pdf)
+ *  History: v1.3.0 - October 18, 2026 - Original release.
+ *           v1.24.0 - October 18, 2026 - Derived module arrays instead of turtle commands.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "image/color"
+    "math"
+    "regexp"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type TimedModule struct {
+    SYMBOL string  //a single L-system symbol or a heading declaration such as "(30)"
+    AGE    float64 //age of the module
+}
+type TimedRule struct {
+    TERMINAL  float64       //terminal age at which the predecessor is replaced by its successor
+    SUCCESSOR []TimedModule //successor modules with their initial ages; nil if the predecessor is never replaced
+}
+func GifGrowth(axiom []TimedModule, rules map[string]TimedRule, growth func(age, terminalAge float64) float64,
+               angle, tStart, tEnd float64, numFrames, width, height, lineWidth, delay int, palette color.Palette,
+               gifPath string) {
+/*         Purpose : Renders the development of a timed L-system between two points in time as an animated GIF. The
+ *                   frames are isometrically scaled and centered to their common bounding box.
+ *       Arguments : axiom     = slice of axiom modules with their initial ages.
+ *                   rules     = map of production rules keyed by the predecessor's symbol.
+ *                   growth    = function mapping a module's age and terminal age to the stride length of "F" and "f"
+ *                               modules. A nil function selects LinearGrowth.
+ *                   angle     = production angle in degrees.
+ *                   tStart    = time of the first frame.
+ *                   tEnd      = time of the last frame.
+ *                   numFrames = number of frames, evenly spaced in time.
+ *                   width     = frame width in pixels.
+ *                   height    = frame height in pixels.
+ *                   lineWidth = line width in pixels.
+ *                   delay     = delay between frames in hundredths of a second.
+ *                   palette   = frame colors: index 0 is the background, the others are cycled by branch depth.
+ *                               A nil palette selects black lines on a white background.
+ *                   gifPath   = file path for the animation.
+ *         Returns : None.
+ * Externals -  In : _defaultPalette, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : deriveTimed, geometries2Gif, halt, modules2Geometry, validTimedRules
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - A module is replaced by its successor once its age reaches the terminal age of its production,
+ *                     the successor modules inheriting the excess age. Rules with a nil successor only declare the
+ *                     terminal age used by the growth function.
+ *                   - "F" and "f" modules without a rule are mature and have unit stride lengths.
+ *                   - Growth is continuous when the stride lengths of the successor modules, at their initial ages,
+ *                     add up to that of the predecessor at its terminal age. For example,
+ *                       rules := map[string]lsystems.TimedRule{
+ *                                  "A": {1., []lsystems.TimedModule{{"F", 0.}, {"[", 0.}, {"+", 0.}, {"A", 0.},
+ *                                                                   {"]", 0.}, {"F", 0.}, {"A", 0.}}},
+ *                                  "F": {2., nil}}
+ *                     grows a plant whose internodes elongate over two time units.
+ *                   - The animation loops forever.
+ *         History : v1.3.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Interpreted the derived module arrays.
+ */
+    if len(axiom) == 0         { halt("axiom was not specified") }
+    validTimedRules(axiom, rules)
+    if angle == 0.             { halt("the production angle is zero") }
+    if tStart < 0. || tEnd < tStart { halt("the time interval is not valid") }
+    if numFrames < 1           { halt("the number of frames must be positive") }
+    if width < 1 || height < 1 { halt("the frame size must be positive") }
+    if lineWidth < 1           { halt("the line width must be positive") }
+    if delay < 0               { halt("the frame delay must be non-negative") }
+    if palette == nil          { palette = _defaultPalette }
+    if len(palette) < 2 || len(palette) > 256 { halt("the palette must have between 2 and 256 colors") }
+    if gifPath == ""           { halt("the path for the animation was not specified") }
+    if growth == nil           { growth = LinearGrowth }
+
+    var geometries []_geometry
+    //Derive and interpret the modules at each point in time
+    for k := 0; k < numFrames; k++ {
+        time := tStart
+        if numFrames > 1 { time += (tEnd - tStart) * float64(k) / float64(numFrames - 1) }
+        modules, strides := deriveTimed(time, axiom, rules, growth)
+        geometries        = append(geometries, modules2Geometry(&modules, angle, strides, ExactTurtle, ProgressBars))
+    }
+    //Render and output the animation
+    geometries2Gif(geometries, width, height, lineWidth, true, delay, palette, gifPath)
+    return
+} //end func GifGrowth
+func LinearGrowth(age, terminalAge float64) float64 {
+/*         Purpose : Growth function increasing linearly from 0 to 1 over the lifetime of a module.
+ *       Arguments : age         = age of the module.
+ *                   terminalAge = terminal age of the module.
+ *         Returns : relative length in [0,1].
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : None.
+ *         History : v1.3.0 - October 18, 2026 - Original release.
+ */
+    return math.Max(0., math.Min(1., age / terminalAge))
+} //end func LinearGrowth
+func LogisticGrowth(age, terminalAge float64) float64 {
+/*         Purpose : Growth function following a logistic (sigmoid) curve from 0 to 1 over the lifetime of a module.
+ *       Arguments : age         = age of the module.
+ *                   terminalAge = terminal age of the module.
+ *         Returns : relative length in [0,1].
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : The curve is rescaled so as to be exactly 0 at birth and 1 at the terminal age.
+ *         History : v1.3.0 - October 18, 2026 - Original release.
+ */
+    const steepness = 10.
+    sigmoid := func(x float64) float64 { return 1. / (1. + math.Exp(-steepness * (x - 0.5))) }
+    x       := math.Max(0., math.Min(1., age / terminalAge))
+    return (sigmoid(x) - sigmoid(0.)) / (sigmoid(1.) - sigmoid(0.))
+} //end func LogisticGrowth
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+var _reTimedSymbol = regexp.MustCompile(`^(.|\([\+\-0-9.]+\))$`)
+func validTimedRules(axiom []TimedModule, rules map[string]TimedRule) {
+    for _, module := range axiom {
+        if !_reTimedSymbol.MatchString(module.SYMBOL) { halt("the axiom modules were not specified correctly") }
+        if module.AGE < 0.                              { halt("the axiom ages must be non-negative") }
+    }
+    for predecessor, rule := range rules {
+        if !_reTimedSymbol.MatchString(predecessor) || !(rule.TERMINAL > 0.) {
+            halt("the rules were not specified correctly")
+        }
+        for _, module := range rule.SUCCESSOR {
+            if !_reTimedSymbol.MatchString(module.SYMBOL) || module.AGE < 0. {
+                halt("the rules were not specified correctly")
+            }
+            //a successor must be younger than its own terminal age, otherwise the derivation would never end
+            if next, ok := rules[module.SYMBOL]; ok && next.SUCCESSOR != nil && !(module.AGE < next.TERMINAL) {
+                halt("the initial age of successor '" + module.SYMBOL + "' must be less than its terminal age")
+            }
+        }
+    }
+    return
+} //end func validTimedRules
+func deriveTimed(time float64, axiom []TimedModule, rules map[string]TimedRule,
+                 growth func(age, terminalAge float64) float64) (modules _modules, strides []float64) {
+    var expand func(module TimedModule)
+    expand = func(module TimedModule) {
+        rule, ok := rules[module.SYMBOL]
+        if ok && rule.SUCCESSOR != nil && module.AGE >= rule.TERMINAL {
+            excess := module.AGE - rule.TERMINAL
+            for _, successor := range rule.SUCCESSOR {
+                expand(TimedModule{successor.SYMBOL, successor.AGE + excess})
+            }
+            return
+        }
+        if err := modules.appendCmds(module.SYMBOL); err != nil { halt(err.Error()) }
+        if module.SYMBOL == "F" || module.SYMBOL == "f" {
+            stride := 1. //mature module
+            if ok { stride = growth(module.AGE, rule.TERMINAL) }
+            strides = append(strides, stride)
+        }
+        return
+    }
+    //Age the axiom and replace each module that reached its terminal age
+    for _, module := range axiom {
+        expand(TimedModule{module.SYMBOL, module.AGE + time})
+    }
+    return
+} //end func deriveTimed
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of timed.go
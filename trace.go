@@ -0,0 +1,181 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      animations revealing the latest generated turtle commands segment by segment, in turtle order.
+ *  Functions:
+ *      GifTrace(angle float64, width, height, lineWidth, numFrames, delay int, palette color.Palette, gifPath string)
+ *          Renders the tracing of the latest generated turtle commands as an animated GIF. The frames are isometrically
+ *          scaled and centered.
+ *      SvgTrace(angle float64, width, height int, lineWidth float64, lineColor, bgColor string, duration float64,
+ *               svgPath string)
+ *          Renders the tracing of the latest generated turtle commands as an animated SVG using stroke-dashoffset.
+ *          The drawing is isometrically scaled and centered.
+ *  History: v1.2.0 - October 18, 2026 - Original release.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "bytes"
+    "fmt"
+    "image"
+    "image/color"
+    "image/gif"
+    "math"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+func GifTrace(angle float64, width, height, lineWidth, numFrames, delay int, palette color.Palette, gifPath string) {
+/*         Purpose : Renders the tracing of the latest generated turtle commands as an animated GIF. The frames are
+ *                   isometrically scaled and centered.
+ *       Arguments : angle     = production angle in degrees.
+ *                   width     = frame width in pixels.
+ *                   height    = frame height in pixels.
+ *                   lineWidth = line width in pixels.
+ *                   numFrames = number of frames, the last one showing the complete drawing.
+ *                   delay     = delay between frames in hundredths of a second.
+ *                   palette   = frame colors: index 0 is the background, the others are cycled by branch depth.
+ *                               A nil palette selects black lines on a white background.
+ *                   gifPath   = file path for the animation.
+ *         Returns : None.
+ * Externals -  In : TurtleCmds, _defaultPalette, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : drawGeometry, fileWrite, halt, logo2Geometry, makeFit2Canvas
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - The segments are revealed in equal batches; polygons appear once closed.
+ *                   - The animation loops forever.
+ *         History : v1.2.0 - October 18, 2026 - Original release.
+ */
+    if TurtleCmds == ""        { halt("the turtle commands were not generated") }
+    if angle      == 0.        { halt("the production angle is zero") }
+    if width < 1 || height < 1 { halt("the frame size must be positive") }
+    if lineWidth < 1           { halt("the line width must be positive") }
+    if numFrames < 1           { halt("the number of frames must be positive") }
+    if delay < 0               { halt("the frame delay must be non-negative") }
+    if palette == nil          { palette = _defaultPalette }
+    if len(palette) < 2 || len(palette) > 256 { halt("the palette must have between 2 and 256 colors") }
+    if gifPath == ""           { halt("the path for the animation was not specified") }
+
+    const margin = 4 //pixels
+    var animation gif.GIF
+    //Interpret the turtle commands
+    geometry    := logo2Geometry(TurtleCmds, angle, nil)
+    fit         := makeFit2Canvas(geometry.XMIN, geometry.XMAX, geometry.YMIN, geometry.YMAX, width, height, margin)
+    numSegments := len(geometry.SEGMENTS)
+    //Render the frames, each one adding the next batch of segments to the previous frame
+    frame := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+    for k := 1; k <= numFrames; k++ {
+        drawGeometry(frame, &geometry, fit, lineWidth, (k * numSegments + numFrames - 1) / numFrames)
+        snapshot := image.NewPaletted(frame.Rect, palette)
+        copy(snapshot.Pix, frame.Pix)
+        animation.Image = append(animation.Image, snapshot)
+        animation.Delay = append(animation.Delay, delay)
+    }
+    //Output the animation to the specified destination
+    var buffer bytes.Buffer
+    if err := gif.EncodeAll(&buffer, &animation); err != nil { halt("gif.EncodeAll - " + err.Error()) }
+    fileWrite(gifPath, buffer.String())
+    return
+} //end func GifTrace
+func SvgTrace(angle float64, width, height int, lineWidth float64, lineColor, bgColor string, duration float64,
+              svgPath string) {
+/*         Purpose : Renders the tracing of the latest generated turtle commands as an animated SVG using
+ *                   stroke-dashoffset. The drawing is isometrically scaled and centered.
+ *       Arguments : angle     = production angle in degrees.
+ *                   width     = image width in pixels.
+ *                   height    = image height in pixels.
+ *                   lineWidth = line width in pixels.
+ *                   lineColor = color of the line segments and polygons, as recognized by SVG.
+ *                   bgColor   = background color, as recognized by SVG, or "" for a transparent background.
+ *                   duration  = duration of the tracing in seconds.
+ *                   svgPath   = file path for the animation.
+ *         Returns : None.
+ * Externals -  In : TurtleCmds, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : chainSegments, fileWrite, halt, logo2Geometry, makeFit2Page
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - Each continuous run of the pen becomes a path whose dash offset is animated at constant speed;
+ *                     polygons fade in once closed.
+ *         History : v1.2.0 - October 18, 2026 - Original release.
+ */
+    if TurtleCmds == ""        { halt("the turtle commands were not generated") }
+    if angle      == 0.        { halt("the production angle is zero") }
+    if width < 1 || height < 1 { halt("the image size must be positive") }
+    if !(lineWidth > 0.)       { halt("the line width must be positive") }
+    if lineColor == ""         { halt("the line color was not specified") }
+    if !(duration > 0.)        { halt("the duration must be positive") }
+    if svgPath == ""           { halt("the path for the animation was not specified") }
+
+    const margin = 4. //pixels
+    var( buffer   bytes.Buffer
+         lengths  []float64 //path lengths in pixels
+         paths    []string  //path data
+         starts   []int     //index of each path's first segment
+         total    float64
+    )
+    //Interpret the turtle commands
+    geometry := logo2Geometry(TurtleCmds, angle, nil)
+    fit      := makeFit2Page(geometry.XMIN, geometry.XMAX, geometry.YMIN, geometry.YMAX,
+                             float64(width), float64(height), margin, true)
+    //Chain the segments into paths and measure them
+    first := 0
+    for _, polyline := range chainSegments(geometry.SEGMENTS) {
+        length := 0.
+        x, y   := fit(polyline[0])
+        data   := fmt.Sprintf("M%.2f,%.2f", x, y)
+        for _, vertex := range polyline[1:] {
+            xTo, yTo := fit(vertex)
+            length   += math.Hypot(xTo - x, yTo - y)
+            data     += fmt.Sprintf("L%.2f,%.2f", xTo, yTo)
+            x, y      = xTo, yTo
+        }
+        lengths = append(lengths, math.Max(length, 0.01))
+        paths   = append(paths, data)
+        starts  = append(starts, first)
+        first  += len(polyline) - 1
+        total  += math.Max(length, 0.01)
+    }
+    //Compose the SVG document
+    fmt.Fprintf(&buffer, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+    fmt.Fprintf(&buffer, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+                width, height, width, height)
+    fmt.Fprintf(&buffer, "<style>\n@keyframes draw { to { stroke-dashoffset: 0; } }\n" +
+                         "@keyframes show { to { opacity: 1; } }\n</style>\n")
+    if bgColor != "" { fmt.Fprintf(&buffer, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", bgColor) }
+    fmt.Fprintf(&buffer, `<g fill="none" stroke="%s" stroke-width="%g" stroke-linecap="round" stroke-linejoin="round">`+"\n",
+                lineColor, lineWidth)
+    elapsed := 0.
+    for k, data := range paths {
+        begin, span := duration * elapsed / total, duration * lengths[k] / total
+        fmt.Fprintf(&buffer, `<path d="%s" style="stroke-dasharray:%.2f;stroke-dashoffset:%.2f;`+
+                             `animation:draw %.3fs linear %.3fs forwards"/>`+"\n",
+                    data, lengths[k], lengths[k], span, begin)
+        elapsed += lengths[k]
+    }
+    fmt.Fprintf(&buffer, "</g>\n")
+    for _, polygon := range geometry.POLYGONS {
+        //locate the point in time at which the polygon's preceding segments are all drawn
+        elapsed = 0.
+        for k := range paths {
+            if starts[k] >= polygon.AFTER { break }
+            elapsed += lengths[k]
+        }
+        points := ""
+        for _, vertex := range polygon.VERTICES {
+            x, y   := fit(vertex)
+            points += fmt.Sprintf("%.2f,%.2f ", x, y)
+        }
+        fmt.Fprintf(&buffer, `<polygon points="%s" fill="%s" stroke="%s" stroke-width="%g" `+
+                             `style="opacity:0;animation:show 0.2s linear %.3fs forwards"/>`+"\n",
+                    points, lineColor, lineColor, lineWidth, duration * elapsed / math.Max(total, 0.01))
+    }
+    fmt.Fprintf(&buffer, "</svg>\n")
+    //Output the animation to the specified destination
+    fileWrite(svgPath, buffer.String())
+    return
+} //end func SvgTrace
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of trace.go
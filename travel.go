@@ -0,0 +1,361 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      pen-travel optimisation of plotter output: segment merging, duplicate removal and stroke reordering.
+ *  Types:
+ *      TravelReport
+ *          Statistics of the latest pen-travel optimisation.
+ *  Variables:
+ *      OptimizePenTravel bool
+ *          Optimises the pen travel of the HP-GL/2 and G-code plots (default: false).
+ *      PenTravel TravelReport
+ *          Statistics of the latest optimised HP-GL/2 or G-code plot.
+ *  Remarks:
+ *      The line segments are deduplicated, merged into polylines at shared vertices, preferably along straight lines,
+ *      and stripped of their collinear interior vertices. The resulting strokes are then ordered by a nearest-neighbour
+ *      heuristic, refined by 2-opt moves, so as to minimise the travel with the pen up. Polygons are left untouched.
+ *      The nearest stroke is looked up in a k-d tree of the stroke ends, so that large plots are ordered in about
+ *      n log n time.
+ *  History: v1.8.0 - October 18, 2026 - Original release.
+ *           v1.9.0 - October 18, 2026 - Extended to G-code plots.
+ *           v1.22.0 - October 18, 2026 - Shared the walk of the merged segments with vertex welding.
+ *           v1.24.0 - October 18, 2026 - Looked up the nearest strokes in a k-d tree.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "math"
+    "sort"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type TravelReport struct {
+    SEGMENTS   int     //number of line segments before optimisation
+    DUPLICATES int     //number of duplicate line segments removed
+    STROKES    int     //number of pen-down strokes after merging
+    BEFORE     float64 //pen-up travel in turtle order, in turtle strides
+    AFTER      float64 //pen-up travel after optimisation, in turtle strides
+}
+var( OptimizePenTravel bool         //optimise the pen travel of the HP-GL/2 and G-code plots
+     PenTravel         TravelReport //statistics of the latest optimised HP-GL/2 or G-code plot
+)
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+const( _vertexTolerance = 1e-6 //turtle strides - vertices closer than this are merged
+       _maxTwoOptStrokes = 2000 //2-opt refinement is skipped for larger stroke counts
+       _maxTwoOptPasses  = 20
+)
+type _vertexKey struct {
+    X, Y int64
+}
+type _edge struct {
+    A, B int //vertex indices
+}
+func resetPenTravel() *TravelReport {
+/*         Purpose : Resets the pen-travel statistics ahead of a plotter plot.
+ *       Arguments : None.
+ *         Returns : pointer to the statistics to accumulate if the optimisation is enabled, nil otherwise.
+ * Externals -  In : OptimizePenTravel
+ * Externals - Out : PenTravel
+ *       Functions : None.
+ *         Remarks : None.
+ *         History : v1.8.0 - October 18, 2026 - Original release.
+ */
+    PenTravel = TravelReport{}
+    if !OptimizePenTravel { return nil }
+    return &PenTravel
+} //end func resetPenTravel
+func optimizeTravel(segments []_segment, report *TravelReport) (polylines [][]_point) {
+/*         Purpose : Merges line segments into polylines and orders them so as to minimise the pen-up travel.
+ *       Arguments : segments = line segments in turtle order.
+ *                   report   = statistics to accumulate.
+ *         Returns : slice of polylines in plotting order, each having at least two vertices.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : chainSegments, mergeSegments, orderStrokes, penDownTravel, penUpTravel
+ *         Remarks : - The pen is assumed to start where the first segment begins.
+ *                   - The turtle order is kept if the optimisation does not shorten the total travel, as happens when
+ *                     removing the duplicates of a retraced curve breaks it into many strokes.
+ *         History : v1.8.0 - October 18, 2026 - Original release.
+ */
+    if len(segments) == 0 { return }
+    start := segments[0].FROM
+    //Measure the travel in turtle order
+    chains := chainSegments(segments)
+    before := penUpTravel(start, chains)
+    //Merge the segments into strokes and order them
+    polylines, duplicates := mergeSegments(segments)
+    polylines              = orderStrokes(start, polylines)
+    after                 := penUpTravel(start, polylines)
+    report.SEGMENTS       += len(segments)
+    report.BEFORE         += before
+    //Keep the turtle order unless the total travel, pen down and pen up, is shortened
+    if after + penDownTravel(polylines) >= before + penDownTravel(chains) {
+        report.STROKES += len(chains)
+        report.AFTER   += before
+        return chains
+    }
+    report.DUPLICATES += duplicates
+    report.STROKES    += len(polylines)
+    report.AFTER      += after
+    return
+} //end func optimizeTravel
+func penUpTravel(start _point, polylines [][]_point) (travel float64) {
+    for _, polyline := range polylines {
+        travel += math.Hypot(polyline[0].X - start.X, polyline[0].Y - start.Y)
+        start   = polyline[len(polyline)-1]
+    }
+    return
+} //end func penUpTravel
+func penDownTravel(polylines [][]_point) (travel float64) {
+    for _, polyline := range polylines {
+        for k := 1; k < len(polyline); k++ {
+            travel += math.Hypot(polyline[k].X - polyline[k-1].X, polyline[k].Y - polyline[k-1].Y)
+        }
+    }
+    return
+} //end func penDownTravel
+func mergeSegments(segments []_segment) (polylines [][]_point, duplicates int) {
+/*         Purpose : Merges line segments sharing vertices into polylines.
+ *       Arguments : segments = line segments in turtle order.
+ *         Returns : polylines = slice of polylines, each having at least two vertices.
+ *                   duplicates = number of duplicate segments that were removed.
+ * Externals -  In : _vertexTolerance
+ * Externals - Out : None.
+ *       Functions : walkEdges
+ *         Remarks : None.
+ *         History : v1.8.0 - October 18, 2026 - Original release.
+ *                   v1.22.0 - October 18, 2026 - Moved the walk to walkEdges.
+ */
+    var( edges    []_edge
+         keys     = make(map[_vertexKey]int)
+         seen     = make(map[_edge]bool)
+         vertices []_point
+    )
+    vertexOf := func(p _point) int {
+        key := _vertexKey{int64(math.Floor(p.X / _vertexTolerance + 0.5)), int64(math.Floor(p.Y / _vertexTolerance + 0.5))}
+        if index, ok := keys[key]; ok { return index }
+        keys[key] = len(vertices)
+        vertices  = append(vertices, p)
+        return len(vertices) - 1
+    }
+    //Build the graph of distinct segments
+    for _, segment := range segments {
+        a, b := vertexOf(segment.FROM), vertexOf(segment.TO)
+        key  := map[bool]_edge{true: {a, b}, false: {b, a}} [a <= b]
+        if seen[key] { duplicates++; continue }
+        seen[key] = true
+        edges     = append(edges, _edge{a, b})
+    }
+    polylines = walkEdges(vertices, edges)
+    return
+} //end func mergeSegments
+func walkEdges(vertices []_point, edges []_edge) (polylines [][]_point) {
+/*         Purpose : Walks a graph of distinct edges into polylines.
+ *       Arguments : vertices = graph vertices.
+ *                   edges    = distinct edges between the vertices.
+ *         Returns : slice of polylines, each having at least two vertices.
+ * Externals -  In : _vertexTolerance
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : - Walks start from vertices of odd degree, where strokes must end anyway, and continue along the
+ *                     straightest unused edge.
+ *                   - Interior vertices lying on a straight line are removed.
+ *         History : v1.22.0 - October 18, 2026 - Original release, split off mergeSegments.
+ */
+    adjacency := make([][]int, len(vertices)) //edge indices incident to each vertex
+    for e, edge := range edges {
+        adjacency[edge.A] = append(adjacency[edge.A], e)
+        if edge.B != edge.A { adjacency[edge.B] = append(adjacency[edge.B], e) }
+    }
+    //Walk the graph, starting from the odd vertices
+    used := make([]bool, len(edges))
+    walk := func(v int) {
+        polyline := []int{v}
+        dx, dy   := 0., 0.
+        for {
+            best, bestDot := -1, math.Inf(-1)
+            for _, e := range adjacency[v] {
+                if used[e] { continue }
+                w      := map[bool]int{true: edges[e].B, false: edges[e].A} [edges[e].A == v]
+                ex, ey := vertices[w].X - vertices[v].X, vertices[w].Y - vertices[v].Y
+                dot    := 0.
+                if norm := math.Hypot(ex, ey); norm > 0. { dot = (dx * ex + dy * ey) / norm }
+                if dot > bestDot { best, bestDot = e, dot }
+            }
+            if best < 0 { break }
+            used[best] = true
+            w         := map[bool]int{true: edges[best].B, false: edges[best].A} [edges[best].A == v]
+            dx, dy     = vertices[w].X - vertices[v].X, vertices[w].Y - vertices[v].Y
+            if norm := math.Hypot(dx, dy); norm > 0. { dx /= norm; dy /= norm }
+            polyline   = append(polyline, w)
+            v          = w
+        }
+        if len(polyline) < 2 { return }
+        //drop the interior vertices lying on a straight line
+        points := []_point{vertices[polyline[0]]}
+        for k := 1; k + 1 < len(polyline); k++ {
+            a, b, c := points[len(points)-1], vertices[polyline[k]], vertices[polyline[k+1]]
+            cross   := (b.X - a.X) * (c.Y - b.Y) - (b.Y - a.Y) * (c.X - b.X)
+            dot     := (b.X - a.X) * (c.X - b.X) + (b.Y - a.Y) * (c.Y - b.Y)
+            if math.Abs(cross) < _vertexTolerance && dot > 0. { continue }
+            points = append(points, b)
+        }
+        polylines = append(polylines, append(points, vertices[polyline[len(polyline)-1]]))
+        return
+    }
+    for _, oddPass := range []bool{true, false} {
+        for v := range vertices {
+            if oddPass && len(adjacency[v]) % 2 == 0 { continue }
+            for k := range adjacency[v] {
+                if !used[adjacency[v][k]] { walk(v) }
+            }
+        }
+    }
+    return
+} //end func walkEdges
+func orderStrokes(start _point, strokes [][]_point) (ordered [][]_point) {
+/*         Purpose : Orders strokes so as to minimise the pen-up travel between them.
+ *       Arguments : start   = initial pen position.
+ *                   strokes = slice of polylines, which may be reversed.
+ *         Returns : slice of polylines in plotting order.
+ * Externals -  In : _maxTwoOptPasses, _maxTwoOptStrokes
+ * Externals - Out : None.
+ *       Functions : makeNearestStroke
+ *         Remarks : A greedy nearest-neighbour tour is refined by 2-opt moves, each reversing a run of strokes, until
+ *                   no move shortens the travel.
+ *         History : v1.8.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Looked up the nearest strokes in a k-d tree.
+ */
+    var( done    = make([]bool, len(strokes))
+         nearest = makeNearestStroke(strokes, done)
+         pen     = start
+    )
+    distance := func(p, q _point) float64 { return math.Hypot(p.X - q.X, p.Y - q.Y) }
+    reverse  := func(polyline []_point) {
+        for i, j := 0, len(polyline) - 1; i < j; i, j = i + 1, j - 1 {
+            polyline[i], polyline[j] = polyline[j], polyline[i]
+        }
+    }
+    //Nearest-neighbour tour
+    for range strokes {
+        best, flip := nearest(pen)
+        done[best]  = true
+        if flip { reverse(strokes[best]) }
+        ordered = append(ordered, strokes[best])
+        pen     = strokes[best][len(strokes[best])-1]
+    }
+    //2-opt refinement: reversing strokes i..j swaps the pen-up moves entering i and leaving j
+    if len(ordered) > _maxTwoOptStrokes { return }
+    head := func(k int) _point { return ordered[k][0] }
+    tail := func(k int) _point {
+        if k < 0 { return start }
+        return ordered[k][len(ordered[k])-1]
+    }
+    for pass, improved := 0, true; improved && pass < _maxTwoOptPasses; pass++ {
+        improved = false
+        for i := 0; i < len(ordered); i++ {
+            for j := i; j < len(ordered); j++ {
+                before := distance(tail(i-1), head(i))
+                after  := distance(tail(i-1), tail(j))
+                if j + 1 < len(ordered) {
+                    before += distance(tail(j), head(j+1))
+                    after  += distance(head(i), head(j+1))
+                }
+                if after < before - 1e-9 {
+                    for a, b := i, j; a <= b; a, b = a + 1, b - 1 {
+                        ordered[a], ordered[b] = ordered[b], ordered[a]
+                        reverse(ordered[a])
+                        if a != b { reverse(ordered[b]) }
+                    }
+                    improved = true
+                }
+            }
+        }
+    }
+    return
+} //end func orderStrokes
+func makeNearestStroke(strokes [][]_point, done []bool) func(pen _point) (best int, flip bool) {
+/*         Purpose : Creates a function finding the stroke with the nearest end to the pen.
+ *       Arguments : strokes = slice of polylines.
+ *                   done    = flags of the strokes already plotted, to be skipped.
+ *         Returns : the search function, returning the index of the nearest stroke and whether it is nearer by its
+ *                   last point.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : - The distinct stroke ends are held in a k-d tree, whose subtrees are skipped once they hold no
+ *                     unplotted stroke, so that a search takes about logarithmic time whatever the spread of the ends.
+ *                   - The strokes sharing an end are queued by index at that point.
+ *                   - Ties go to the lower stroke index, then to the first point, as with a scan of all the strokes.
+ *         History : v1.24.0 - October 18, 2026 - Original release.
+ */
+    var( index  = make(map[_point]int)
+         points []_point
+         ends   [][]int //ends at each point in increasing order, end e being the first point of stroke e/2 if e is even
+         next   []int   //position of the first unplotted end at each point
+    )
+    for k, stroke := range strokes {
+        for e, p := range []_point{stroke[0], stroke[len(stroke)-1]} {
+            n, found := index[p]
+            if !found {
+                n, index[p] = len(points), len(points)
+                points      = append(points, p)
+                ends        = append(ends, nil)
+                next        = append(next, 0)
+            }
+            ends[n] = append(ends[n], 2 * k + e)
+        }
+    }
+    //Build the k-d tree in place: the median of order[lo:hi] is the node, splitting on X at even depths, on Y otherwise
+    var( order = make([]int, len(points))
+         alive = make([]int, len(points)) //number of points with unplotted ends in the subtree of each node
+    )
+    for n := range order { order[n] = n }
+    coord := func(p _point, depth int) float64 {
+        if depth % 2 == 0 { return p.X }
+        return p.Y
+    }
+    var build func(lo, hi, depth int)
+    build = func(lo, hi, depth int) {
+        if lo >= hi { return }
+        sub := order[lo:hi]
+        sort.Slice(sub, func(i, j int) bool { return coord(points[sub[i]], depth) < coord(points[sub[j]], depth) })
+        mid       := (lo + hi) / 2
+        alive[mid] = hi - lo
+        build(lo, mid, depth + 1)
+        build(mid + 1, hi, depth + 1)
+    }
+    build(0, len(order), 0)
+    return func(pen _point) (best int, flip bool) {
+            var( bestEnd      = -1
+                 bestDistance = math.Inf(1)
+                 search       func(lo, hi, depth int) (removed int)
+            )
+            search = func(lo, hi, depth int) (removed int) {
+                mid := (lo + hi) / 2
+                if lo >= hi || alive[mid] == 0 { return 0 }
+                n := order[mid]
+                for next[n] < len(ends[n]) && done[ends[n][next[n]] / 2] { next[n]++ }
+                switch {
+                    case next[n] == len(ends[n]): //the point is spent: drop it from the counts once
+                        next[n], removed = next[n] + 1, 1
+                    case next[n] < len(ends[n]):
+                        e := ends[n][next[n]]
+                        d := math.Hypot(pen.X - points[n].X, pen.Y - points[n].Y)
+                        if d < bestDistance || d == bestDistance && e < bestEnd { bestEnd, bestDistance = e, d }
+                }
+                //search the side of the pen first, then the other side if it could hold an end as near
+                diff      := coord(points[n], depth) - coord(pen, depth)
+                near, far := [2]int{lo, mid}, [2]int{mid + 1, hi}
+                if diff < 0. { near, far = far, near }
+                removed += search(near[0], near[1], depth + 1)
+                if math.Abs(diff) <= bestDistance { removed += search(far[0], far[1], depth + 1) }
+                alive[mid] -= removed
+                return
+            }
+            search(0, len(order), 0)
+            return bestEnd / 2, bestEnd % 2 == 1
+           }
+} //end func makeNearestStroke
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of travel.go
@@ -0,0 +1,289 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      branch trees of bracketed turtle interpretations and their export as JSON, GraphML or DOT graphs.
+ *  Types:
+ *      BranchFormat
+ *          Graph format of a branch tree export: BranchJson, BranchGraphml or BranchDot.
+ *      BranchNode
+ *          Unbranched run of line segments of a branch tree.
+ *      BranchTree
+ *          Branching structure of a turtle interpretation.
+ *  Functions:
+ *      BranchStructure(angle float64) BranchTree
+ *          Converts the latest generated turtle commands to a branch tree.
+ *      BranchExport(tree BranchTree, format BranchFormat, exportPath string)
+ *          Exports a branch tree to a file.
+ *      BranchExportWriter(writer io.Writer, tree BranchTree, format BranchFormat)
+ *          Exports a branch tree to an io.Writer.
+ *  Remarks:
+ *      A node is a maximal run of connected line segments drawn without crossing a bracket. A node ends where a branch
+ *      starts, so that its children are the lateral branches opened at its end followed by its continuation after them,
+ *      as in "F[+F][-F]F" where the first F has three children. A gap in the drawing, as left by "f", also ends a node
+ *      and its successor becomes a child. Nodes are numbered in turtle order, so that parents precede their children.
+ *      The Horton-Strahler order of a node is 1 for a leaf and otherwise the highest order of its children, plus one if
+ *      two or more children share it. A Horton stream of order k is a maximal chain of nodes of order k.
+ *  History: v1.19.0 - October 18, 2026 - Original release.
+ *           v1.20.0 - October 18, 2026 - Added Horton-Strahler orders.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "math"
+    "strconv"
+    "strings"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type BranchTree struct {
+    ROOTS []int        `json:"roots"` //identifiers of the nodes without a parent
+    NODES []BranchNode `json:"nodes"` //nodes indexed by their identifier
+}
+type BranchNode struct {
+    ID       int     `json:"id"`       //identifier, i.e., index in BranchTree.NODES
+    PARENT   int     `json:"parent"`   //identifier of the parent node, or -1 for a root
+    X        float64 `json:"x"`        //start position in turtle strides
+    Y        float64 `json:"y"`
+    END_X    float64 `json:"end_x"`    //end position in turtle strides
+    END_Y    float64 `json:"end_y"`
+    HEADING  float64 `json:"heading"`  //orientation of the first segment in degrees counterclockwise from the x axis
+    LENGTH   float64 `json:"length"`   //total length of the segments in turtle strides
+    SEGMENTS int     `json:"segments"` //number of line segments
+    DEPTH    int     `json:"depth"`    //branch depth, i.e., the number of unmatched "[" when the node was drawn
+    STRAHLER int     `json:"strahler"` //Horton-Strahler order
+    CHILDREN []int   `json:"children"` //identifiers of the child nodes in turtle order
+}
+type BranchFormat int
+const(
+    BranchJson    BranchFormat = iota //JSON object with the "roots" and "nodes" of BranchTree
+    BranchGraphml                     //GraphML directed graph with the node fields as data keys
+    BranchDot                         //Graphviz DOT digraph with the node fields as attributes
+)
+func BranchStructure(angle float64) BranchTree {
+/*         Purpose : Converts the latest generated turtle commands to a branch tree.
+ *       Arguments : angle = production angle in degrees.
+ *         Returns : the branch tree.
+ * Externals -  In : TurtleCmds, _degs2rads, _turtleHistory, _turtleStatus, _vertexTolerance
+ * Externals - Out : None.
+ *       Functions : branchNodes, halt, logo2Geometry, strahlerOrders
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { } ; ,
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - Polygons are not part of the tree.
+ *                   - Coordinates, headings and lengths are rounded to 1e-9 to drop floating-point noise.
+ *         History : v1.19.0 - October 18, 2026 - Original release.
+ *                   v1.20.0 - October 18, 2026 - Added Horton-Strahler orders.
+ */
+    if TurtleCmds == "" { halt("the turtle commands were not generated") }
+    if angle      == 0. { halt("the production angle is zero") }
+
+    round := func(v float64) float64 { return math.Round(v * 1e9) / 1e9 }
+    //Interpret the turtle commands
+    geometry := logo2Geometry(TurtleCmds, angle, nil)
+    //Group the segments into nodes and order them
+    tree, _ := branchNodes(&geometry)
+    strahlerOrders(&tree)
+    for k := range tree.NODES {
+        node := &tree.NODES[k]
+        node.X, node.Y, node.END_X, node.END_Y = round(node.X), round(node.Y), round(node.END_X), round(node.END_Y)
+        node.HEADING, node.LENGTH              = math.Mod(round(node.HEADING), 360.), round(node.LENGTH)
+    }
+    return tree
+} //end func BranchStructure
+func BranchExport(tree BranchTree, format BranchFormat, exportPath string) {
+/*         Purpose : Exports a branch tree to a file.
+ *       Arguments : tree       = branch tree.
+ *                   format     = graph format.
+ *                   exportPath = file path for the graph.
+ *         Returns : None.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : BranchExportWriter, fileWrite, halt
+ *         Remarks : See BranchExportWriter.
+ *         History : v1.19.0 - October 18, 2026 - Original release.
+ */
+    if exportPath == "" { halt("the path for the graph was not specified") }
+
+    var buffer bytes.Buffer
+    BranchExportWriter(&buffer, tree, format)
+    fileWrite(exportPath, buffer.String())
+    return
+} //end func BranchExport
+func BranchExportWriter(writer io.Writer, tree BranchTree, format BranchFormat) {
+/*         Purpose : Exports a branch tree to an io.Writer.
+ *       Arguments : writer = destination of the graph.
+ *                   tree   = branch tree.
+ *                   format = graph format.
+ *         Returns : None.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : halt, streamWrite
+ *         Remarks : - Graph nodes are named "n<ID>" in GraphML and DOT, with an edge from each parent to its children.
+ *                   - DOT attributes other than "label" are not Graphviz attributes and are ignored when rendering.
+ *         History : v1.19.0 - October 18, 2026 - Original release.
+ *                   v1.20.0 - October 18, 2026 - Added Horton-Strahler orders.
+ */
+    var graph strings.Builder
+    number := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+    switch format {
+        case BranchJson:
+            content, err := json.MarshalIndent(tree, "", "  ")
+            if err != nil { halt("json.MarshalIndent - " + err.Error()) }
+            graph.Write(content)
+            graph.WriteString("\n")
+        case BranchGraphml:
+            graph.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+            graph.WriteString("<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\">\n")
+            for _, key := range []string{"x double", "y double", "end_x double", "end_y double", "heading double",
+                                         "length double", "segments int", "depth int", "strahler int"} {
+                fields := strings.Fields(key)
+                fmt.Fprintf(&graph, "  <key id=%q for=\"node\" attr.name=%q attr.type=%q/>\n", fields[0], fields[0],
+                            fields[1])
+            }
+            graph.WriteString("  <graph id=\"branches\" edgedefault=\"directed\">\n")
+            for _, node := range tree.NODES {
+                fmt.Fprintf(&graph, "    <node id=\"n%d\">\n", node.ID)
+                for _, data := range [][2]string{{"x", number(node.X)}, {"y", number(node.Y)},
+                                                 {"end_x", number(node.END_X)}, {"end_y", number(node.END_Y)},
+                                                 {"heading", number(node.HEADING)}, {"length", number(node.LENGTH)},
+                                                 {"segments", strconv.Itoa(node.SEGMENTS)},
+                                                 {"depth", strconv.Itoa(node.DEPTH)},
+                                                 {"strahler", strconv.Itoa(node.STRAHLER)}} {
+                    fmt.Fprintf(&graph, "      <data key=%q>%s</data>\n", data[0], data[1])
+                }
+                graph.WriteString("    </node>\n")
+            }
+            for _, node := range tree.NODES {
+                for _, child := range node.CHILDREN {
+                    fmt.Fprintf(&graph, "    <edge source=\"n%d\" target=\"n%d\"/>\n", node.ID, child)
+                }
+            }
+            graph.WriteString("  </graph>\n</graphml>\n")
+        case BranchDot:
+            graph.WriteString("digraph branches {\n")
+            for _, node := range tree.NODES {
+                fmt.Fprintf(&graph, "  n%d [label=\"%d\", x=%q, y=%q, end_x=%q, end_y=%q, heading=%q, length=%q, " +
+                                    "segments=%d, depth=%d, strahler=%d];\n", node.ID, node.ID, number(node.X), number(node.Y),
+                            number(node.END_X), number(node.END_Y), number(node.HEADING), number(node.LENGTH),
+                            node.SEGMENTS, node.DEPTH, node.STRAHLER)
+            }
+            for _, node := range tree.NODES {
+                for _, child := range node.CHILDREN {
+                    fmt.Fprintf(&graph, "  n%d -> n%d;\n", node.ID, child)
+                }
+            }
+            graph.WriteString("}\n")
+        default:
+            halt("unknown branch tree format")
+    }
+    streamWrite(writer, graph.String())
+    return
+} //end func BranchExportWriter
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+func branchNodes(geometry *_geometry) (tree BranchTree, nodeOf []int) {
+/*         Purpose : Groups the line segments of turtle geometry into the nodes of a branch tree.
+ *       Arguments : geometry = turtle geometry.
+ *         Returns : tree   = branch tree with unrounded coordinates and without Horton-Strahler orders.
+ *                   nodeOf = identifier of the node of each line segment.
+ * Externals -  In : _degs2rads, _vertexTolerance
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : None.
+ *         History : v1.20.0 - October 18, 2026 - Original release, split off BranchStructure.
+ */
+    var( current = -1    //node being drawn, or -1 after a bracket or a gap
+         attach  = -1    //parent of the next node
+         stack   []int   //attachment points saved by "["
+         mark    = 0     //next bracket to process
+    )
+    tree   = BranchTree{ROOTS: []int{}, NODES: []BranchNode{}}
+    nodeOf = make([]int, len(geometry.SEGMENTS))
+    for k, segment := range geometry.SEGMENTS {
+        for ; mark < len(geometry.BRACKETS) && geometry.BRACKETS[mark].AFTER == k; mark++ {
+            if current >= 0 { attach, current = current, -1 }
+            if geometry.BRACKETS[mark].OPEN {
+                stack = append(stack, attach)
+            } else {
+                attach, stack = stack[len(stack)-1], stack[:len(stack)-1]
+            }
+        }
+        if current >= 0 {
+            node := &tree.NODES[current]
+            if math.Hypot(segment.FROM.X - node.END_X, segment.FROM.Y - node.END_Y) > _vertexTolerance { //gap
+                attach, current = current, -1
+            }
+        }
+        dx, dy := segment.TO.X - segment.FROM.X, segment.TO.Y - segment.FROM.Y
+        if current < 0 {
+            current = len(tree.NODES)
+            heading := math.Mod(math.Atan2(dy, dx) / _degs2rads + 360., 360.)
+            tree.NODES = append(tree.NODES, BranchNode{ID: current, PARENT: attach, X: segment.FROM.X,
+                                                       Y: segment.FROM.Y, HEADING: heading, DEPTH: segment.DEPTH,
+                                                       CHILDREN: []int{}})
+            if attach < 0 {
+                tree.ROOTS = append(tree.ROOTS, current)
+            } else {
+                tree.NODES[attach].CHILDREN = append(tree.NODES[attach].CHILDREN, current)
+            }
+        }
+        node         := &tree.NODES[current]
+        node.END_X    = segment.TO.X
+        node.END_Y    = segment.TO.Y
+        node.LENGTH  += math.Hypot(dx, dy)
+        node.SEGMENTS++
+        nodeOf[k]     = current
+    }
+    return
+} //end func branchNodes
+func strahlerOrders(tree *BranchTree) {
+/*         Purpose : Computes the Horton-Strahler order of the nodes of a branch tree.
+ *       Arguments : tree = branch tree.
+ *         Returns : None.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : The nodes are visited in reverse turtle order, so that the children are ordered before their parent.
+ *         History : v1.20.0 - October 18, 2026 - Original release.
+ */
+    for k := len(tree.NODES) - 1; k >= 0; k-- {
+        node   := &tree.NODES[k]
+        order  := 1
+        shared := 0
+        for _, child := range node.CHILDREN {
+            switch childOrder := tree.NODES[child].STRAHLER; {
+                case childOrder > order:
+                    order, shared = childOrder, 1
+                case childOrder == order:
+                    shared++
+            }
+        }
+        node.STRAHLER = order + map[bool]int{true: 1, false: 0} [shared > 1]
+    }
+    return
+} //end func strahlerOrders
+func assignStrahler(geometry *_geometry) {
+/*         Purpose : Sets the Horton-Strahler order of the line segments and polygons of turtle geometry.
+ *       Arguments : geometry = turtle geometry.
+ *         Returns : None.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : branchNodes, strahlerOrders
+ *         Remarks : Polygons, typically leaves or petals, are given order 1.
+ *         History : v1.20.0 - October 18, 2026 - Original release.
+ */
+    tree, nodeOf := branchNodes(geometry)
+    strahlerOrders(&tree)
+    for k := range geometry.SEGMENTS {
+        geometry.SEGMENTS[k].ORDER = tree.NODES[nodeOf[k]].STRAHLER
+    }
+    for k := range geometry.POLYGONS {
+        geometry.POLYGONS[k].ORDER = 1
+    }
+    return
+} //end func assignStrahler
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of tree.go
@@ -0,0 +1,383 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      pure-Go PDF and EPS vector output, requiring no external tools.
+ *  Types:
+ *      LineCap
+ *          Shape of the line ends: CapButt, CapRound or CapSquare.
+ *      LineJoin
+ *          Shape of the line corners: JoinMiter, JoinRound or JoinBevel.
+ *      VectorPage
+ *          Page settings for PDF and EPS output.
+ *  Variables:
+ *      PageA3, PageA4, PageLetter VectorPage
+ *          Common page sizes in portrait orientation with 10 mm margins, round caps and joins, and black lines.
+ *  Functions:
+ *      EpsPlot(angle float64, plotTitle string, page VectorPage, epsPath string)
+ *          Renders the latest generated turtle commands as an Encapsulated PostScript file. The drawing is isometrically
+ *          scaled and centered on the page.
+ *      PdfPlot(angle float64, plotTitle string, page VectorPage, pdfPath string)
+ *          Renders the latest generated turtle commands as a single-page PDF document. The drawing is isometrically
+ *          scaled and centered on the page.
+ *      PdfMultiPage(turtleCmds []string, turtleAngles []float64, plotTitle string, labels []string, commonFit bool,
+ *                   page VectorPage, pdfPath string)
+ *          Renders a set of turtle commands as a PDF document with one page per subplot. Each drawing is isometrically
+ *          scaled and centered on its page.
+ *  Remarks:
+ *      The text is set in the standard Helvetica font, which every PDF and PostScript interpreter provides. Polygons are
+ *      filled with the even-odd rule and outlined.
+ *  History: v1.11.0 - October 18, 2026 - Original release.
+ *           v1.22.0 - October 18, 2026 - Added vertex welding.
+ *           v1.24.0 - October 18, 2026 - Added the line width by Horton-Strahler order.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "bytes"
+    "compress/zlib"
+    "fmt"
+    "image/color"
+    "math"
+    "strings"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+type VectorPage struct {
+    WIDTH          float64     //page width in millimeters
+    HEIGHT         float64     //page height in millimeters
+    LANDSCAPE      bool        //true to swap the width and height
+    MARGIN         float64     //blank border in millimeters
+    LINE_WIDTH     float64     //line width in millimeters
+    LINE_CAP       LineCap     //shape of the line ends
+    LINE_JOIN      LineJoin    //shape of the line corners
+    LINE_COLOR     color.Color //color of the lines and text; nil for black
+    FILL_COLOR     color.Color //color of the polygons; nil for the line color
+    WIDTH_BY_ORDER bool        //true to multiply the width of each line segment by its Horton-Strahler order
+}
+type LineCap int
+const(
+    CapButt   LineCap = iota //lines end squarely at their endpoints
+    CapRound                 //lines end with semicircles
+    CapSquare                //lines end squarely, extended by half the line width
+)
+type LineJoin int
+const(
+    JoinMiter LineJoin = iota //sharp corners
+    JoinRound                 //rounded corners
+    JoinBevel                 //cut-off corners
+)
+var( PageA3     = VectorPage{WIDTH: 297.,  HEIGHT: 420.,  MARGIN: 10., LINE_WIDTH: 0.3, LINE_CAP: CapRound,
+                             LINE_JOIN: JoinRound} //ISO A3
+     PageA4     = VectorPage{WIDTH: 210.,  HEIGHT: 297.,  MARGIN: 10., LINE_WIDTH: 0.3, LINE_CAP: CapRound,
+                             LINE_JOIN: JoinRound} //ISO A4
+     PageLetter = VectorPage{WIDTH: 215.9, HEIGHT: 279.4, MARGIN: 10., LINE_WIDTH: 0.3, LINE_CAP: CapRound,
+                             LINE_JOIN: JoinRound} //US Letter
+)
+func EpsPlot(angle float64, plotTitle string, page VectorPage, epsPath string) {
+/*         Purpose : Renders the latest generated turtle commands as an Encapsulated PostScript file. The drawing is
+ *                   isometrically scaled and centered on the page.
+ *       Arguments : angle     = production angle in degrees.
+ *                   plotTitle = title to be centered at the top of the page.
+ *                   page      = page settings.
+ *                   epsPath   = file path for the drawing.
+ *         Returns : None.
+ * Externals -  In : TurtleCmds, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : assignStrahler, fileWrite, halt, logo2Geometry, pageContent, validPage, weldGeometry
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - The bounding box is the whole page.
+ *         History : v1.11.0 - October 18, 2026 - Original release.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ *                   v1.24.0 - October 18, 2026 - Added the line width by Horton-Strahler order.
+ */
+    if TurtleCmds == "" { halt("the turtle commands were not generated") }
+    if angle      == 0. { halt("the production angle is zero") }
+    validPage(page)
+    if epsPath    == "" { halt("the path for the drawing was not specified") }
+
+    var buffer bytes.Buffer
+    //Interpret the turtle commands
+    geometry := logo2Geometry(TurtleCmds, angle, nil)
+    if page.WIDTH_BY_ORDER { assignStrahler(&geometry) }
+    weldGeometry(&geometry)
+    width, height, content := pageContent(&geometry, geometry.XMIN, geometry.XMAX, geometry.YMIN, geometry.YMAX,
+                                          plotTitle, "", page, _psOperators)
+    //Compose the EPS document, defining the PDF operators used by the page content
+    fmt.Fprintf(&buffer, "%%!PS-Adobe-3.0 EPSF-3.0\n%%%%BoundingBox: 0 0 %.0f %.0f\n" +
+                         "%%%%HiResBoundingBox: 0 0 %.3f %.3f\n%%%%Creator: lsystems\n%%%%Title: %s\n" +
+                         "%%%%LanguageLevel: 2\n%%%%EndComments\n",
+                math.Ceil(width), math.Ceil(height), width, height,
+                map[bool]string{true: plotTitle, false: "L-system"} [plotTitle != ""])
+    fmt.Fprintf(&buffer, "%%%%BeginProlog\n/m {moveto} bind def /l {lineto} bind def /S {stroke} bind def\n" +
+                         "/RG {setrgbcolor} bind def /w {setlinewidth} bind def\n" +
+                         "/J {setlinecap} bind def /j {setlinejoin} bind def\n" +
+                         "/b* {closepath gsave FC eofill grestore stroke} bind def\n" +
+                         "/Tx {/Helvetica findfont exch scalefont setfont\n" +
+                         "     exch 2 index stringwidth pop 2 div sub exch moveto show} bind def\n%%%%EndProlog\n")
+    fmt.Fprintf(&buffer, "gsave\n%sgrestore\nshowpage\n%%%%EOF\n", content)
+    //Output the drawing to the specified destination
+    fileWrite(epsPath, buffer.String())
+    return
+} //end func EpsPlot
+func PdfPlot(angle float64, plotTitle string, page VectorPage, pdfPath string) {
+/*         Purpose : Renders the latest generated turtle commands as a single-page PDF document. The drawing is
+ *                   isometrically scaled and centered on the page.
+ *       Arguments : angle     = production angle in degrees.
+ *                   plotTitle = title to be centered at the top of the page.
+ *                   page      = page settings.
+ *                   pdfPath   = file path for the document.
+ *         Returns : None.
+ * Externals -  In : TurtleCmds, _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : assignStrahler, composePdf, fileWrite, halt, logo2Geometry, pageContent, validPage, weldGeometry
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *         History : v1.11.0 - October 18, 2026 - Original release.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ *                   v1.24.0 - October 18, 2026 - Added the line width by Horton-Strahler order.
+ */
+    if TurtleCmds == "" { halt("the turtle commands were not generated") }
+    if angle      == 0. { halt("the production angle is zero") }
+    validPage(page)
+    if pdfPath    == "" { halt("the path for the document was not specified") }
+
+    //Interpret the turtle commands
+    geometry := logo2Geometry(TurtleCmds, angle, nil)
+    if page.WIDTH_BY_ORDER { assignStrahler(&geometry) }
+    weldGeometry(&geometry)
+    width, height, content := pageContent(&geometry, geometry.XMIN, geometry.XMAX, geometry.YMIN, geometry.YMAX,
+                                          plotTitle, "", page, _pdfOperators)
+    //Output the document to the specified destination
+    fileWrite(pdfPath, composePdf(width, height, []string{content}))
+    return
+} //end func PdfPlot
+func PdfMultiPage(turtleCmds []string, turtleAngles []float64, plotTitle string, labels []string, commonFit bool,
+                  page VectorPage, pdfPath string) {
+/*         Purpose : Renders a set of turtle commands as a PDF document with one page per subplot. Each drawing is
+ *                   isometrically scaled and centered on its page.
+ *       Arguments : turtleCmds   = slice of turtle commands.
+ *                   turtleAngles = slice of production angles in degrees.
+ *                   plotTitle    = title to be centered at the top of every page.
+ *                   labels       = slice of labels to be centered at the bottom of each page, or nil.
+ *                   commonFit    = true to draw all the subplots at the same scale, false to fit each one to its page.
+ *                   page         = page settings.
+ *                   pdfPath      = file path for the document.
+ *         Returns : None.
+ * Externals -  In : _degs2rads, _turtleHistory, _turtleStatus
+ * Externals - Out : None.
+ *       Functions : assignStrahler, composePdf, fileWrite, halt, logo2Geometry, pageContent, validPage, weldGeometry
+ *         Remarks : - Supported L-system constants are F f + - | $ ( ) [ ] { }
+ *                     All other symbols will be ignored.
+ *                   - The default turtle heading is 0 degrees.
+ *                   - With a common fit, the subplots share the scale of the largest one and are centered on their
+ *                     pages.
+ *         History : v1.11.0 - October 18, 2026 - Original release.
+ *                   v1.22.0 - October 18, 2026 - Added vertex welding.
+ *                   v1.24.0 - October 18, 2026 - Added the line width by Horton-Strahler order.
+ */
+    if len(turtleCmds)   == 0 { halt("the turtle commands were not specified") }
+    if len(turtleAngles) < len(turtleCmds) {
+        halt("fewer turtle angles specified than the number of commands")
+    }
+    if labels != nil && len(labels) < len(turtleCmds) {
+        halt("fewer labels specified than the number of commands")
+    }
+    for _, v := range turtleAngles[:len(turtleCmds)] {
+        if v == 0. { halt("a production angle is zero") }
+    }
+    validPage(page)
+    if pdfPath == "" { halt("the path for the document was not specified") }
+
+    var( contents   []string
+         geometries = make([]_geometry, len(turtleCmds))
+         width      float64
+         height     float64
+         xSpan      float64
+         ySpan      float64
+    )
+    //Interpret the turtle commands and find the largest extents
+    for k, v := range turtleCmds {
+        geometries[k] = logo2Geometry(v, turtleAngles[k], nil)
+        if page.WIDTH_BY_ORDER { assignStrahler(&geometries[k]) }
+        weldGeometry(&geometries[k])
+        xSpan         = math.Max(xSpan, geometries[k].XMAX - geometries[k].XMIN)
+        ySpan         = math.Max(ySpan, geometries[k].YMAX - geometries[k].YMIN)
+    }
+    //Render the pages
+    for k := range geometries {
+        xMin, xMax, yMin, yMax := geometries[k].XMIN, geometries[k].XMAX, geometries[k].YMIN, geometries[k].YMAX
+        if commonFit { //widen the bounding box to the largest extents, keeping it centered
+            xMid, yMid := 0.5 * (xMin + xMax), 0.5 * (yMin + yMax)
+            xMin, xMax  = xMid - 0.5 * xSpan, xMid + 0.5 * xSpan
+            yMin, yMax  = yMid - 0.5 * ySpan, yMid + 0.5 * ySpan
+        }
+        label := ""
+        if labels != nil { label = labels[k] }
+        var content string
+        width, height, content = pageContent(&geometries[k], xMin, xMax, yMin, yMax, plotTitle, label, page,
+                                             _pdfOperators)
+        contents = append(contents, content)
+    }
+    //Output the document to the specified destination
+    fileWrite(pdfPath, composePdf(width, height, contents))
+    return
+} //end func PdfMultiPage
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+type _vectorOperators struct {
+    PROLOG string //operators setting the line and fill colors, given the stroke and fill RGB components
+    FILL   string //operator closing, filling and stroking a polygon
+}
+const _ptPerMm = 72. / 25.4 //PostScript points per millimeter
+var( _pdfOperators = _vectorOperators{"%.3f %.3f %.3f RG %.3f %.3f %.3f rg\n", "h b*\n"}
+     _psOperators  = _vectorOperators{"%.3f %.3f %.3f RG /FC {%.3f %.3f %.3f setrgbcolor} def\n", "b*\n"}
+     //Helvetica character widths in thousandths of the font size, for the codes 32 to 126
+     _helveticaWidths = [95]int{278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+                                556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+                                1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+                                667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+                                333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+                                556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584}
+)
+func validPage(page VectorPage) {
+    if !(page.WIDTH > 0.) || !(page.HEIGHT > 0.) { halt("the page size must be positive") }
+    if page.MARGIN < 0. || 2. * page.MARGIN >= math.Min(page.WIDTH, page.HEIGHT) { halt("the page margin is not valid") }
+    if !(page.LINE_WIDTH > 0.) { halt("the line width must be positive") }
+    if page.LINE_CAP  < CapButt   || page.LINE_CAP  > CapSquare { halt("the line cap is not valid") }
+    if page.LINE_JOIN < JoinMiter || page.LINE_JOIN > JoinBevel { halt("the line join is not valid") }
+    return
+} //end func validPage
+func pageContent(geometry *_geometry, xMin, xMax, yMin, yMax float64, title, label string, page VectorPage,
+                 operators _vectorOperators) (width, height float64, content string) {
+/*         Purpose : Composes the PDF content stream of a page, which also serves as the EPS page description.
+ *       Arguments : geometry               = turtle geometry.
+ *                   xMin, xMax, yMin, yMax = bounding box to fit to the page.
+ *                   title                  = title to be centered at the top of the page.
+ *                   label                  = label to be centered at the bottom of the page.
+ *                   page                   = page settings.
+ *                   operators              = format-specific operators.
+ *         Returns : the page size in points and the page content.
+ * Externals -  In : _ptPerMm
+ * Externals - Out : None.
+ *       Functions : chainSegments, makeFit2Page, rgbComponents, splitSegments, textWidth
+ *         Remarks : - The PostScript prolog of EPS files defines the PDF operators used here.
+ *                   - Segments whose Horton-Strahler order is assigned are drawn that many times thicker.
+ *         History : v1.11.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Added the line width by Horton-Strahler order.
+ */
+    const fontSize = 14. //points
+    var buffer bytes.Buffer
+    width, height = page.WIDTH * _ptPerMm, page.HEIGHT * _ptPerMm
+    if page.LANDSCAPE { width, height = height, width }
+    margin        := page.MARGIN * _ptPerMm
+    top, bottom   := map[bool]float64{true: 2. * fontSize, false: 0.} [title != ""],
+                     map[bool]float64{true: 2. * fontSize, false: 0.} [label != ""]
+    fit           := makeFit2Page(xMin, xMax, yMin, yMax, width, height - top - bottom, margin, false)
+    coords        := func(p _point) (x, y float64) {
+        x, y = fit(p)
+        return x, y + bottom
+    }
+    //set the line style and colors
+    lineColor, fillColor := page.LINE_COLOR, page.FILL_COLOR
+    if lineColor == nil { lineColor = color.Black }
+    if fillColor == nil { fillColor = lineColor }
+    r, g, b    := rgbComponents(lineColor)
+    fr, fg, fb := rgbComponents(fillColor)
+    fmt.Fprintf(&buffer, "%.3f w %d J %d j\n", page.LINE_WIDTH * _ptPerMm, page.LINE_CAP, page.LINE_JOIN)
+    fmt.Fprintf(&buffer, operators.PROLOG, r, g, b, fr, fg, fb)
+    //draw the polygons, then the line segments
+    for _, polygon := range geometry.POLYGONS {
+        for k, vertex := range polygon.VERTICES {
+            x, y := coords(vertex)
+            fmt.Fprintf(&buffer, "%.3f %.3f %s\n", x, y, map[bool]string{true: "m", false: "l"} [k == 0])
+        }
+        buffer.WriteString(operators.FILL)
+    }
+    for _, run := range splitSegments(geometry.SEGMENTS, func(depth, color, order int) int { return order }, false) {
+        if run.KEY > 0 { fmt.Fprintf(&buffer, "%.3f w\n", page.LINE_WIDTH * _ptPerMm * float64(run.KEY)) }
+        for _, polyline := range chainSegments(run.SEGMENTS) {
+            for k, vertex := range polyline {
+                x, y := coords(vertex)
+                fmt.Fprintf(&buffer, "%.3f %.3f %s\n", x, y, map[bool]string{true: "m", false: "l"} [k == 0])
+            }
+            buffer.WriteString("S\n")
+        }
+    }
+    //draw the title and label, in the line color
+    text := func(s string, y float64) {
+        s = strings.Map(func(c rune) rune {
+                            if c < 32 || c > 126 { return '?' }
+                            return c
+                        }, s)
+        escaped := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+        if operators == _pdfOperators {
+            fmt.Fprintf(&buffer, "%.3f %.3f %.3f rg BT /F1 %.0f Tf %.3f %.3f Td (%s) Tj ET\n", r, g, b, fontSize,
+                        0.5 * (width - textWidth(s, fontSize)), y, escaped)
+        } else {
+            fmt.Fprintf(&buffer, "%.3f %.3f %.3f RG (%s) %.3f %.3f %.0f Tx\n", r, g, b, escaped, 0.5 * width, y, fontSize)
+        }
+    }
+    if title != "" { text(title, height - margin - 1.3 * fontSize) }
+    if label != "" { text(label, margin + 0.5 * fontSize) }
+    content = buffer.String()
+    return
+} //end func pageContent
+func composePdf(width, height float64, contents []string) string {
+/*         Purpose : Composes a PDF document from the content streams of its pages.
+ *       Arguments : width, height = page size in points.
+ *                   contents      = slice of page content streams.
+ *         Returns : the document.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : halt
+ *         Remarks : Objects 1 to 3 are the catalog, the page tree and the font; each page then takes two objects, the
+ *                   page and its compressed content stream.
+ *         History : v1.11.0 - October 18, 2026 - Original release.
+ */
+    var( buffer  bytes.Buffer
+         kids    []string
+         offsets []int
+    )
+    object := func(body string) {
+        offsets = append(offsets, buffer.Len())
+        fmt.Fprintf(&buffer, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+    }
+    for k := range contents {
+        kids = append(kids, fmt.Sprintf("%d 0 R", 4 + 2 * k))
+    }
+    buffer.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+    object("<< /Type /Catalog /Pages 2 0 R >>")
+    object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(contents)))
+    object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+    for k, content := range contents {
+        var stream bytes.Buffer
+        compressor := zlib.NewWriter(&stream)
+        if _, err := compressor.Write([]byte(content)); err != nil { halt("zlib.Write - " + err.Error()) }
+        if err := compressor.Close(); err != nil { halt("zlib.Close - " + err.Error()) }
+        object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.3f %.3f] " +
+                           "/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", width, height, 5 + 2 * k))
+        object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.String()))
+    }
+    //cross-reference table and trailer
+    xref := buffer.Len()
+    fmt.Fprintf(&buffer, "xref\n0 %d\n0000000000 65535 f \n", len(offsets) + 1)
+    for _, offset := range offsets {
+        fmt.Fprintf(&buffer, "%010d 00000 n \n", offset)
+    }
+    fmt.Fprintf(&buffer, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets) + 1, xref)
+    return buffer.String()
+} //end func composePdf
+func rgbComponents(c color.Color) (r, g, b float64) {
+    r16, g16, b16, _ := c.RGBA()
+    return float64(r16) / 65535., float64(g16) / 65535., float64(b16) / 65535.
+} //end func rgbComponents
+func textWidth(s string, fontSize float64) (width float64) {
+    for _, c := range s {
+        width += float64(_helveticaWidths[c - 32])
+    }
+    return width * fontSize / 1000.
+} //end func textWidth
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of vector.go
@@ -0,0 +1,188 @@
+/*===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+ *  Package:
+ *      lsystems
+ *  Overview:
+ *      vertex welding and polyline merging of the turtle geometry ahead of rendering.
+ *  Variables:
+ *      WeldTolerance float64
+ *          Distance, in turtle strides, within which vertices are welded (default: 1e-6).
+ *      WeldVertices bool
+ *          Welds the vertices and merges the line segments into polylines before rendering (default: false).
+ *  Remarks:
+ *      Vertices lying within the tolerance of an earlier vertex are snapped onto it. The line segments reduced to a point
+ *      and the repeated ones, in either direction, are then dropped, and the remaining segments of each branch depth,
+ *      color index and Horton-Strahler order are merged into polylines at their shared vertices, straight runs being
+ *      reduced to single segments. The segments are finally listed polyline after polyline, so that every renderer
+ *      chains them into as few pen strokes as possible. Gnuplot plots the polylines as inline data instead of one
+ *      arrow per segment.
+ *      The turtle order is lost in the process, so that the traces and the analyses always use the raw geometry.
+ *  History: v1.22.0 - October 18, 2026 - Original release.
+ *============================================================================================================================*/
+package lsystems
+
+import(
+    "errors"
+    "fmt"
+    "math"
+)
+/*Exported -------------------------------------------------------------------------------------------------------------------*/
+var( WeldTolerance = _weldTolerance //turtle strides - vertices closer than this are welded
+     WeldVertices  bool           //weld the vertices and merge the line segments into polylines before rendering
+)
+/*Private  -------------------------------------------------------------------------------------------------------------------*/
+const _weldTolerance = 1e-6 //turtle strides - default weld tolerance
+type _segmentStyle struct {
+    DEPTH int //branch depth
+    COLOR int //color index
+    ORDER int //Horton-Strahler order
+}
+func weldGeometry(geometry *_geometry) {
+    //welds according to WeldVertices and WeldTolerance, halting on an invalid tolerance
+    if !WeldVertices { return }
+    if err := checkWeld(WeldVertices, WeldTolerance); err != nil { halt(err.Error()) }
+    weldWithin(geometry, WeldTolerance)
+    return
+} //end func weldGeometry
+func weldWithin(geometry *_geometry, tolerance float64) {
+/*         Purpose : Welds the vertices of turtle geometry and merges its line segments into polylines.
+ *       Arguments : geometry  = turtle geometry, modified in place.
+ *                   tolerance = validated distance in turtle strides within which vertices are welded.
+ *         Returns : None.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : walkEdges, weldPoints
+ *         Remarks : - Of repeated segments, the first one drawn is kept.
+ *                   - Polygons are welded too, and dropped when fewer than three distinct vertices remain.
+ *                   - The branch brackets no longer match the segments and are discarded.
+ *         History : v1.22.0 - October 18, 2026 - Original release.
+ *                   v1.24.0 - October 18, 2026 - Took the tolerance as an argument.
+ */
+    var( groups   = make(map[_segmentStyle][]_edge)
+         local    []int //vertex index within the group being merged, plus one
+         points   []_point
+         polygons []_polygon
+         seen     = make(map[_edge]bool, len(geometry.SEGMENTS))
+         styles   []_segmentStyle
+         weld     = weldPoints(tolerance, len(geometry.SEGMENTS))
+    )
+    //Weld the segments, dropping the degenerate and repeated ones, and group them by style
+    for _, segment := range geometry.SEGMENTS {
+        from, a := weld(segment.FROM)
+        to,   b := weld(segment.TO)
+        if a == len(points) { points = append(points, from) }
+        if b == len(points) { points = append(points, to) }
+        key := _edge{a, b}
+        if b < a { key = _edge{b, a} }
+        if a == b || seen[key] { continue }
+        seen[key] = true
+        style    := _segmentStyle{segment.DEPTH, segment.COLOR, segment.ORDER}
+        if _, ok := groups[style]; !ok { styles = append(styles, style) }
+        groups[style] = append(groups[style], _edge{a, b})
+    }
+    //Merge each group into polylines, listed segment by segment
+    geometry.SEGMENTS = geometry.SEGMENTS[:0]
+    local             = make([]int, len(points))
+    for _, style := range styles {
+        var( edges    []_edge
+             vertices []int
+        )
+        localOf := func(v int) int {
+            if local[v] == 0 {
+                vertices = append(vertices, v)
+                local[v] = len(vertices)
+            }
+            return local[v] - 1
+        }
+        for _, edge := range groups[style] {
+            edges = append(edges, _edge{localOf(edge.A), localOf(edge.B)})
+        }
+        coords := make([]_point, len(vertices))
+        for k, v := range vertices {
+            coords[k], local[v] = points[v], 0
+        }
+        for _, polyline := range walkEdges(coords, edges) {
+            for k := 1; k < len(polyline); k++ {
+                geometry.SEGMENTS = append(geometry.SEGMENTS,
+                                           _segment{polyline[k-1], polyline[k], style.DEPTH, style.COLOR, style.ORDER})
+            }
+        }
+    }
+    //Weld the polygons
+    for _, polygon := range geometry.POLYGONS {
+        var vertices []_point
+        for _, vertex := range polygon.VERTICES {
+            vertex, _ = weld(vertex)
+            if len(vertices) == 0 || vertex != vertices[len(vertices)-1] { vertices = append(vertices, vertex) }
+        }
+        distinct := len(vertices)
+        if distinct > 1 && vertices[distinct-1] == vertices[0] { distinct-- }
+        if distinct < 3 { continue }
+        polygon.VERTICES = vertices
+        polygon.AFTER    = int(math.Min(float64(polygon.AFTER), float64(len(geometry.SEGMENTS))))
+        polygons         = append(polygons, polygon)
+    }
+    geometry.POLYGONS, geometry.BRACKETS = polygons, nil
+    return
+} //end func weldWithin
+func checkWeld(weld bool, tolerance float64) error {
+    if weld && !(tolerance > 0.) { return errors.New("the weld tolerance must be positive") }
+    return nil
+} //end func checkWeld
+func weldPoints(tolerance float64, capacity int) func(p _point) (_point, int) {
+/*         Purpose : Creates a function snapping points onto the earlier points lying within a tolerance.
+ *       Arguments : tolerance = welding distance.
+ *                   capacity  = expected number of distinct points.
+ *         Returns : the snapping function, returning the snapped point and its index among the distinct points.
+ * Externals -  In : None.
+ * Externals - Out : None.
+ *       Functions : None.
+ *         Remarks : The points are hashed on a grid 16 times coarser than the tolerance, so that a single cell
+ *                   needs to be searched, save near the cell boundaries.
+ *         History : v1.22.0 - October 18, 2026 - Original release.
+ */
+    var( cell   = 16. * tolerance
+         cells  = make(map[_vertexKey][]int, capacity)
+         points = make([]_point, 0, capacity)
+    )
+    return func(p _point) (_point, int) {
+            //the points within reach lie in the cell of the point, or at most 2 x 2 cells near a corner
+            xLow, xHigh    := int64(math.Floor((p.X - tolerance) / cell)), int64(math.Floor((p.X + tolerance) / cell))
+            yLow, yHigh    := int64(math.Floor((p.Y - tolerance) / cell)), int64(math.Floor((p.Y + tolerance) / cell))
+            best, bestDist := -1, math.Inf(1)
+            for cx := xLow; cx <= xHigh; cx++ {
+                for cy := yLow; cy <= yHigh; cy++ {
+                    for _, index := range cells[_vertexKey{cx, cy}] {
+                        q := points[index]
+                        if dist := math.Hypot(q.X - p.X, q.Y - p.Y); dist <= tolerance && dist < bestDist {
+                            best, bestDist = index, dist
+                        }
+                    }
+                }
+            }
+            if best < 0 {
+                key       := _vertexKey{int64(math.Floor(p.X / cell)), int64(math.Floor(p.Y / cell))}
+                best       = len(points)
+                points     = append(points, p)
+                cells[key] = append(cells[key], best)
+            }
+            return points[best], best
+           }
+} //end func weldPoints
+func polylines2Gnuplot(polylines [][]_point, fit func(p _point) _point) (plotData []string) {
+    for _, polyline := range polylines { //blank lines separate the polylines
+        for _, vertex := range polyline {
+            q       := fit(vertex)
+            plotData = append(plotData, fmt.Sprintf("%f %f", q.X, q.Y))
+        }
+        plotData = append(plotData, "")
+    }
+    return
+} //end func polylines2Gnuplot
+func gnuplotPlotCmd(lineColor string, plotData []string) (plotCmds []string) {
+    if plotData == nil { return []string{fmt.Sprintf(`plot 0,0 notitle lc rgb "%s" lw 0`, lineColor)} }
+    plotCmds = append(plotCmds, fmt.Sprintf(`plot '-' with lines notitle lc rgb "%s"`, lineColor))
+    plotCmds = append(plotCmds, plotData...)
+    return append(plotCmds, "e")
+} //end func gnuplotPlotCmd
+//===== Copyright (c) 2016 Yves Beaudoin - All rights reserved - MIT LICENSE (MIT) - Email: webpraxis@gmail.com ================
+//end of weld.go